@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func datasourceDTrace() *schema.Resource {
+	return &schema.Resource{
+		SchemaVersion: 1,
+		Read:          dtraceDatasourceReadFunc,
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"uuid": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"script": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"config": &schema.Schema{
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dtraceDatasourceReadFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	name := d.Get("name").(string)
+	script, found, err := client.FindDTrace(name)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return fmt.Errorf("DTrace script %s was not found", name)
+	}
+
+	d.Set("uuid", script.UUID)
+	d.Set("script", script.Script)
+	d.Set("config", flattenMetadata(script.Config))
+	d.SetId(script.UUID)
+
+	return nil
+}
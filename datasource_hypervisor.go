@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func datasourceHypervisor() *schema.Resource {
+	attributes := hypervisorAttributes()
+	attributes["uuid"] = &schema.Schema{
+		Type:          schema.TypeString,
+		Optional:      true,
+		Computed:      true,
+		ConflictsWith: []string{"alias"},
+	}
+	attributes["alias"] = &schema.Schema{
+		Type:          schema.TypeString,
+		Optional:      true,
+		Computed:      true,
+		ConflictsWith: []string{"uuid"},
+	}
+
+	return &schema.Resource{
+		SchemaVersion: 1,
+		Read:          hypervisorDatasourceReadFunc,
+		Schema:        attributes,
+	}
+}
+
+// hypervisorAttributes lists the computed attributes shared by the
+// projectfifo_hypervisor and projectfifo_hypervisors data sources.
+func hypervisorAttributes() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"host": &schema.Schema{
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"characteristics": &schema.Schema{
+			Type:     schema.TypeMap,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"total_memory": &schema.Schema{
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"provisioned_memory": &schema.Schema{
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"free_memory": &schema.Schema{
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"pools": &schema.Schema{
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"sysinfo_version": &schema.Schema{
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"version": &schema.Schema{
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"virtualisation": &schema.Schema{
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+	}
+}
+
+// getHypervisorCharacteristics renders characteristic values as strings,
+// FiFo allows numbers and booleans as well.
+func getHypervisorCharacteristics(hv Hypervisor) map[string]string {
+	characteristics := make(map[string]string, len(hv.Characteristics))
+	for key, value := range hv.Characteristics {
+		characteristics[key] = fmt.Sprint(value)
+	}
+
+	return characteristics
+}
+
+func flattenHypervisor(hv Hypervisor) map[string]interface{} {
+	pools := make([]string, 0, len(hv.Pools))
+	for name := range hv.Pools {
+		pools = append(pools, name)
+	}
+	sort.Strings(pools)
+
+	sysinfoVersion := ""
+	if liveImage, ok := hv.Sysinfo["Live Image"]; ok {
+		sysinfoVersion = fmt.Sprint(liveImage)
+	}
+
+	return map[string]interface{}{
+		"uuid":               hv.UUID,
+		"alias":              hv.Alias,
+		"host":               hv.Host,
+		"characteristics":    getHypervisorCharacteristics(hv),
+		"total_memory":       hv.Resources.TotalMemory,
+		"provisioned_memory": hv.Resources.ProvisionedMemory,
+		"free_memory":        hv.Resources.FreeMemory,
+		"pools":              pools,
+		"sysinfo_version":    sysinfoVersion,
+		"version":            hv.Version,
+		"virtualisation":     hv.Virtualisation,
+	}
+}
+
+func hypervisorDatasourceReadFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	uuid := d.Get("uuid").(string)
+	if uuid == "" {
+		alias := d.Get("alias").(string)
+		if alias == "" {
+			return fmt.Errorf("One of uuid or alias must be specified")
+		}
+
+		hv, found, err := client.FindHypervisor(alias)
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			return fmt.Errorf("Hypervisor %s was not found", alias)
+		}
+
+		uuid = hv.UUID
+	}
+
+	hv, err := client.GetHypervisor(uuid)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range flattenHypervisor(hv) {
+		d.Set(key, value)
+	}
+	d.SetId(hv.UUID)
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func datasourceHypervisors() *schema.Resource {
+	attributes := hypervisorAttributes()
+	attributes["uuid"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+	attributes["alias"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+
+	return &schema.Resource{
+		SchemaVersion: 1,
+		Read:          hypervisorsDatasourceReadFunc,
+		Schema: map[string]*schema.Schema{
+			"characteristics": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"uuids": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"hypervisors": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: attributes,
+				},
+			},
+		},
+	}
+}
+
+// hypervisorMatches reports whether a hypervisor has every one of the
+// requested characteristics.
+func hypervisorMatches(hv Hypervisor, characteristics map[string]interface{}) bool {
+	actual := getHypervisorCharacteristics(hv)
+	for key, value := range characteristics {
+		if v, ok := actual[key]; !ok || v != value.(string) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hypervisorsDatasourceReadFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	hypervisors, err := client.ListHypervisors()
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(hypervisors, func(i, j int) bool {
+		return hypervisors[i].Alias < hypervisors[j].Alias
+	})
+
+	characteristics := d.Get("characteristics").(map[string]interface{})
+
+	uuids := make([]string, 0, len(hypervisors))
+	result := make([]interface{}, 0, len(hypervisors))
+	for _, hv := range hypervisors {
+		if !hypervisorMatches(hv, characteristics) {
+			continue
+		}
+
+		uuids = append(uuids, hv.UUID)
+		result = append(result, flattenHypervisor(hv))
+	}
+
+	d.Set("uuids", uuids)
+	d.Set("hypervisors", result)
+	d.SetId(strconv.Itoa(schema.HashString(strings.Join(uuids, ","))))
+
+	return nil
+}
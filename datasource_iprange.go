@@ -1,6 +1,10 @@
 package main
 
-import "github.com/hashicorp/terraform/helper/schema"
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
 
 func datasourceIpRange() *schema.Resource {
 	return &schema.Resource{
@@ -8,12 +12,16 @@ func datasourceIpRange() *schema.Resource {
 		Read:          iprangeDatasourceReadFunc,
 		Schema: map[string]*schema.Schema{ // List of supported configuration fields for your resource
 			"name": &schema.Schema{
-				Type:     schema.TypeString,
-				Computed: true,
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"uuid", "tag"},
 			},
 			"tag": &schema.Schema{
-				Type:     schema.TypeString,
-				Computed: true,
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"uuid", "name"},
 			},
 			"network": &schema.Schema{
 				Type:     schema.TypeString,
@@ -39,9 +47,24 @@ func datasourceIpRange() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"free_count": &schema.Schema{
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"used_count": &schema.Schema{
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"allocated_ips": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
 			"uuid": &schema.Schema{
-				Type:     schema.TypeString,
-				Required: true,
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"name", "tag"},
 			},
 		},
 	}
@@ -50,9 +73,24 @@ func datasourceIpRange() *schema.Resource {
 func iprangeDatasourceReadFunc(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*FifoClient)
 
-	uuid := d.Get("uuid").(string)
-
-	iprange, err := client.GetIpRange(uuid)
+	var iprange *IPRange
+	var found bool
+	var err error
+	if uuid := d.Get("uuid").(string); uuid != "" {
+		iprange, err = client.GetIpRange(uuid)
+	} else if name := d.Get("name").(string); name != "" {
+		iprange, found, err = client.FindIpRange(name)
+		if err == nil && !found {
+			err = fmt.Errorf("IP range %s was not found", name)
+		}
+	} else if tag := d.Get("tag").(string); tag != "" {
+		iprange, found, err = client.FindIpRangeByTag(tag)
+		if err == nil && !found {
+			err = fmt.Errorf("IP range tagged %s was not found", tag)
+		}
+	} else {
+		err = fmt.Errorf("One of uuid, name or tag must be specified")
+	}
 	if err != nil {
 		return err
 	}
@@ -65,6 +103,9 @@ func iprangeDatasourceReadFunc(d *schema.ResourceData, meta interface{}) error {
 	d.Set("vlan", iprange.Vlan)
 	d.Set("first", iprange.First)
 	d.Set("last", iprange.Last)
+	d.Set("free_count", len(iprange.Free))
+	d.Set("used_count", len(iprange.Used))
+	d.Set("allocated_ips", []string(iprange.Used))
 	d.Set("uuid", iprange.UUID)
 	d.SetId(iprange.UUID)
 
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// datasourceIpRangeFreeIp looks up the lowest address of an iprange that is
+// currently free. It doesn't reserve anything: the address is only claimed
+// once a VM is created with it, so use exclude to pick distinct addresses
+// for several VMs in the same plan.
+func datasourceIpRangeFreeIp() *schema.Resource {
+	return &schema.Resource{
+		SchemaVersion: 1,
+		Read:          iprangeFreeIpDatasourceReadFunc,
+		Schema: map[string]*schema.Schema{
+			"iprange": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"exclude": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"ip": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func iprangeFreeIpDatasourceReadFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	iprange, err := client.GetIpRange(d.Get("iprange").(string))
+	if err != nil {
+		return err
+	}
+
+	first, err := ipToUint32(iprange.First)
+	if err != nil {
+		return err
+	}
+
+	last, err := ipToUint32(iprange.Last)
+	if err != nil {
+		return err
+	}
+
+	taken := map[string]bool{
+		iprange.Gateway: true,
+	}
+	for _, ip := range iprange.Used {
+		taken[ip] = true
+	}
+	for _, ip := range d.Get("exclude").([]interface{}) {
+		taken[ip.(string)] = true
+	}
+
+	// address >= first stops the loop should last be the highest address.
+	for address := first; address <= last && address >= first; address++ {
+		ip := uint32ToIP(address)
+		if taken[ip] {
+			continue
+		}
+
+		d.Set("ip", ip)
+		d.SetId(iprange.UUID + ":" + ip)
+
+		return nil
+	}
+
+	return fmt.Errorf("IP range %s has no free addresses", iprange.Name)
+}
@@ -19,6 +19,46 @@ func datasourceNetwork() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"ipranges": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"uuid": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"tag": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"gateway": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"netmask": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"vlan": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"first": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"last": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -36,7 +76,27 @@ func networkDatasourceReadFunc(d *schema.ResourceData, meta interface{}) error {
 		return fmt.Errorf("Network %s was not found", name)
 	}
 
+	ipranges := make([]interface{}, 0, len(nw.IPRanges))
+	for _, uuid := range nw.IPRanges {
+		iprange, err := client.GetIpRange(uuid)
+		if err != nil {
+			return err
+		}
+
+		ipranges = append(ipranges, map[string]interface{}{
+			"uuid":    iprange.UUID,
+			"name":    iprange.Name,
+			"tag":     iprange.Tag,
+			"gateway": iprange.Gateway,
+			"netmask": iprange.Netmask,
+			"vlan":    iprange.Vlan,
+			"first":   iprange.First,
+			"last":    iprange.Last,
+		})
+	}
+
 	d.Set("uuid", nw.UUID)
+	d.Set("ipranges", ipranges)
 	d.SetId(nw.UUID)
 
 	return nil
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func datasourceVm() *schema.Resource {
+	return &schema.Resource{
+		SchemaVersion: 1,
+		Read:          vmDatasourceReadFunc,
+		Schema: map[string]*schema.Schema{
+			"uuid": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"alias"},
+			},
+			"alias": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"uuid"},
+			},
+			"hostname": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"state": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"package": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"dataset": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"hypervisor": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"owner": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"ip": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"networks": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"ip": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"netmask": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"gateway": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"mac": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"resolvers": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"routes": &schema.Schema{
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"dns_domain": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"metadata": &schema.Schema{
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func flattenVMNetworks(networks []VMNetworkConfig) []interface{} {
+	result := make([]interface{}, 0, len(networks))
+	for _, nic := range networks {
+		result = append(result, map[string]interface{}{
+			"ip":      nic.IP,
+			"netmask": nic.Netmask,
+			"gateway": nic.Gateway,
+			"mac":     nic.MAC,
+		})
+	}
+
+	return result
+}
+
+// findVmByAlias looks up the VM with the given alias and fails if the alias
+// is not unique.
+func findVmByAlias(client *FifoClient, alias string) (VM, error) {
+	vms, err := client.ListVms()
+	if err != nil {
+		return VM{}, err
+	}
+
+	var matches []VM
+	for _, vm := range vms {
+		if vm.Config.Alias == alias {
+			matches = append(matches, vm)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return VM{}, fmt.Errorf("VM %s was not found", alias)
+	case 1:
+		return matches[0], nil
+	}
+
+	return VM{}, fmt.Errorf("Alias %s matches %d VMs, use the uuid instead", alias, len(matches))
+}
+
+func vmDatasourceReadFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	var vm VM
+	var err error
+	if uuid := d.Get("uuid").(string); uuid != "" {
+		vm, err = client.GetVm(uuid)
+	} else if alias := d.Get("alias").(string); alias != "" {
+		vm, err = findVmByAlias(client, alias)
+	} else {
+		err = fmt.Errorf("One of uuid or alias must be specified")
+	}
+	if err != nil {
+		return err
+	}
+
+	ip := ""
+	if ips := getVMIPs(vm); len(ips) > 0 {
+		ip = ips[0]
+	}
+
+	d.Set("uuid", vm.UUID)
+	d.Set("alias", vm.Config.Alias)
+	d.Set("hostname", vm.Config.Hostname)
+	d.Set("state", vm.State)
+	d.Set("package", vm.Package)
+	d.Set("dataset", vm.Dataset)
+	d.Set("hypervisor", vm.Hypervisor)
+	d.Set("owner", vm.Owner)
+	d.Set("ip", ip)
+	d.Set("networks", flattenVMNetworks(vm.Config.Networks))
+	d.Set("resolvers", vm.Config.Resolvers)
+	d.Set("routes", vm.Config.Routes)
+	d.Set("dns_domain", vm.Config.DNSDomain)
+	d.Set("metadata", flattenMetadata(vm.Metadata))
+	d.SetId(vm.UUID)
+
+	return nil
+}
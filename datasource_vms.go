@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func datasourceVms() *schema.Resource {
+	return &schema.Resource{
+		SchemaVersion: 1,
+		Read:          vmsDatasourceReadFunc,
+		Schema: map[string]*schema.Schema{
+			"alias_regex": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.ValidateRegexp,
+			},
+			"state": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"owner": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"package": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"dataset": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"hypervisor": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"metadata": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"uuids": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"vms": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"uuid": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"alias": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"hostname": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"state": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"ips": &schema.Schema{
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// getMetadataValue looks up a dot separated path, e.g. "terraform.env", in
+// a metadata document.
+func getMetadataValue(metadata map[string]interface{}, path string) (string, bool) {
+	var value interface{} = metadata
+	for _, segment := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+
+		value, ok = m[segment]
+		if !ok {
+			return "", false
+		}
+	}
+
+	return fmt.Sprint(value), true
+}
+
+func getVMIPs(vm VM) []string {
+	ips := make([]string, 0, len(vm.Config.Networks))
+	for _, nic := range vm.Config.Networks {
+		if nic.IP != "" {
+			ips = append(ips, nic.IP)
+		}
+	}
+
+	return ips
+}
+
+func vmMatches(d *schema.ResourceData, vm VM, aliasRegex *regexp.Regexp) bool {
+	if aliasRegex != nil && !aliasRegex.MatchString(vm.Config.Alias) {
+		return false
+	}
+
+	filters := map[string]string{
+		"state":      vm.State,
+		"owner":      vm.Owner,
+		"package":    vm.Package,
+		"dataset":    vm.Dataset,
+		"hypervisor": vm.Hypervisor,
+	}
+	for key, actual := range filters {
+		if expected := d.Get(key).(string); expected != "" && !strings.EqualFold(expected, actual) {
+			return false
+		}
+	}
+
+	for path, expected := range d.Get("metadata").(map[string]interface{}) {
+		if actual, ok := getMetadataValue(vm.Metadata, path); !ok || actual != expected.(string) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func vmsDatasourceReadFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	var aliasRegex *regexp.Regexp
+	if pattern := d.Get("alias_regex").(string); pattern != "" {
+		var err error
+		aliasRegex, err = regexp.Compile(pattern)
+		if err != nil {
+			return err
+		}
+	}
+
+	vms, err := client.ListVms()
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(vms, func(i, j int) bool {
+		return vms[i].Config.Alias < vms[j].Config.Alias
+	})
+
+	uuids := make([]string, 0, len(vms))
+	result := make([]interface{}, 0, len(vms))
+	for _, vm := range vms {
+		if !vmMatches(d, vm, aliasRegex) {
+			continue
+		}
+
+		uuids = append(uuids, vm.UUID)
+		result = append(result, map[string]interface{}{
+			"uuid":     vm.UUID,
+			"alias":    vm.Config.Alias,
+			"hostname": vm.Config.Hostname,
+			"state":    vm.State,
+			"ips":      getVMIPs(vm),
+		})
+	}
+
+	d.Set("uuids", uuids)
+	d.Set("vms", result)
+	d.SetId(strconv.Itoa(schema.HashString(strings.Join(uuids, ","))))
+
+	return nil
+}
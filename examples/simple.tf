@@ -15,6 +15,19 @@ provider "projectfifo" {
 #              will be used
 #    example:
 #       api_key = "1234567890ABCD"
+
+#    default_org - The UUID of the organization new VMs are assigned to when they don't specify an owner.  If not specified,
+#                  the TF_FIFO_DEFAULT_ORG environment variable will be used
+#    example:
+#       default_org = "00000000-0000-0000-0000-000000000000"
+
+#    default_metadata - Metadata stamped on every object the provider creates, stored under the "terraform" metadata key
+#                       together with managed_by = "terraform".  Terraform doesn't tell providers the current
+#                       workspace, so workspace is required and should be set to "${terraform.workspace}".
+#    example:
+#       default_metadata {
+#           workspace = "${terraform.workspace}"
+#       }
 }
 
 # Data source declaration for existing Project Fifo data.
@@ -74,6 +87,11 @@ resource "projectfifo_vm" "example_vm2" {
         }
         hostname = "vm2"
     }
+    kvm {
+        nic_driver = "virtio"
+        disk_driver = "virtio"
+        cpu_type = "host"
+    }
 }
 
 resource "projectfifo_vm" "example_vm3" {
@@ -88,6 +106,11 @@ resource "projectfifo_vm" "example_vm3" {
         }
         hostname = "vm3"
     }
+    kvm {
+        nic_driver = "virtio"
+        disk_driver = "virtio"
+        cpu_type = "host"
+    }
 }
 
 /*
@@ -7,17 +7,29 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 )
 
 type FifoClient struct {
 	ApiKey     string
 	Endpoint   string
+	DefaultOrg string
 	Timeout    int
 	MaxRetries int
 	NetworkMap map[string]string
 	PackageMap map[string]string
 	DatasetMap map[string]string
+
+	HypervisorMap map[string]string
+	IPRangeMap    map[string]string
+	IPRangeTagMap map[string][]string
+	DTraceMap     map[string]string
+
+	DefaultTags       map[string]interface{}
+	DefaultMetadata   map[string]interface{}
+	MetadataNamespace string
 }
 
 type errorReply struct {
@@ -95,6 +107,18 @@ func CheckResponse(res *http.Response) error {
 }
 
 func (c *FifoClient) SendRequest(method string, api string, body io.Reader) ([]byte, error) {
+	return c.sendRequest(method, api, body, nil)
+}
+
+// SendFullListRequest lists a collection with the x-full-list header set, so
+// FiFo returns the complete objects instead of only their UUIDs.
+func (c *FifoClient) SendFullListRequest(api string) ([]byte, error) {
+	return c.sendRequest("GET", api, nil, map[string]string{
+		"x-full-list": "true",
+	})
+}
+
+func (c *FifoClient) sendRequest(method string, api string, body io.Reader, headers map[string]string) ([]byte, error) {
 	request, err := http.NewRequest(method, c.Endpoint+api, body)
 	if err != nil {
 		return nil, err
@@ -103,6 +127,9 @@ func (c *FifoClient) SendRequest(method string, api string, body io.Reader) ([]b
 	request.Header.Set("Content-Type", "application/json;charset=UTF-8")
 	request.Header.Set("Authorization", "Bearer "+c.ApiKey)
 	request.Header.Set("Accept", "application/json")
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
 
 	client := &http.Client{}
 	response, err := client.Do(request)
@@ -341,6 +368,20 @@ func (c *FifoClient) GetVm(uuid string) (VM, error) {
 	return vm, nil
 }
 
+func (c *FifoClient) ListVms() ([]VM, error) {
+	response, err := c.SendFullListRequest("/api/3/vms")
+	if err != nil {
+		return nil, err
+	}
+
+	var vms []VM
+	if err := json.Unmarshal(response, &vms); err != nil {
+		return nil, err
+	}
+
+	return vms, nil
+}
+
 func (c *FifoClient) VmExists(uuid string) bool {
 	_, err := c.SendRequest("GET", "/api/3/vms/"+uuid, nil)
 
@@ -375,6 +416,76 @@ func (c *FifoClient) CreateIpRange(m *IPRange) (string, error) {
 	return uuid.(string), nil
 }
 
+func (c *FifoClient) CacheIpRangeList() error {
+	response, err := c.SendRequest("GET", "/api/3/ipranges", nil)
+	if err != nil {
+		return err
+	}
+
+	var ipranges []string
+	if err := json.Unmarshal(response, &ipranges); err != nil {
+		return err
+	}
+
+	for _, uuid := range ipranges {
+		iprange, err := c.GetIpRange(uuid)
+		if err != nil {
+			return err
+		}
+
+		c.IPRangeMap[iprange.Name] = uuid
+		c.IPRangeTagMap[iprange.Tag] = append(c.IPRangeTagMap[iprange.Tag], uuid)
+	}
+
+	return nil
+}
+
+func (c *FifoClient) cacheIpRanges() error {
+	if len(c.IPRangeMap) == 0 {
+		c.IPRangeMap = make(map[string]string)
+		c.IPRangeTagMap = make(map[string][]string)
+		return c.CacheIpRangeList()
+	}
+
+	return nil
+}
+
+func (c *FifoClient) FindIpRange(name string) (*IPRange, bool, error) {
+	if err := c.cacheIpRanges(); err != nil {
+		return nil, false, err
+	}
+
+	uuid, found := c.IPRangeMap[name]
+	if !found {
+		return nil, false, nil
+	}
+
+	iprange, err := c.GetIpRange(uuid)
+
+	return iprange, err == nil, err
+}
+
+// FindIpRangeByTag returns the iprange carrying the given tag. It fails if
+// more than one iprange is tagged with it.
+func (c *FifoClient) FindIpRangeByTag(tag string) (*IPRange, bool, error) {
+	if err := c.cacheIpRanges(); err != nil {
+		return nil, false, err
+	}
+
+	uuids := c.IPRangeTagMap[tag]
+	if len(uuids) == 0 {
+		return nil, false, nil
+	}
+
+	if len(uuids) > 1 {
+		return nil, false, fmt.Errorf("Tag %s matches %d ipranges", tag, len(uuids))
+	}
+
+	iprange, err := c.GetIpRange(uuids[0])
+
+	return iprange, err == nil, err
+}
+
 func (c *FifoClient) GetIpRange(uuid string) (*IPRange, error) {
 	response, err := c.SendRequest("GET", "/api/3/ipranges/"+uuid, nil)
 	if err != nil {
@@ -405,3 +516,544 @@ func (c *FifoClient) DeleteIpRange(uuid string) error {
 
 	return err
 }
+
+func (c *FifoClient) GetUser(uuid string) (User, error) {
+	user := User{}
+
+	response, err := c.SendRequest("GET", "/api/3/users/"+uuid, nil)
+	if err != nil {
+		return user, err
+	}
+
+	if err := json.Unmarshal(response, &user); err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+func (c *FifoClient) AddVmFwRule(uuid string, rule *VMFwRule) error {
+	jsonDocument, _ := json.Marshal(rule)
+
+	_, err := c.SendRequest("POST", "/api/3/vms/"+uuid+"/fw_rules", bytes.NewBuffer(jsonDocument))
+
+	return err
+}
+
+func (c *FifoClient) DeleteVmFwRule(uuid string, ruleID int) error {
+	_, err := c.SendRequest("DELETE", "/api/3/vms/"+uuid+"/fw_rules/"+strconv.Itoa(ruleID), nil)
+
+	return err
+}
+
+// UpdateVmConfig changes the given config keys of an existing VM. Only the
+// keys present in config are sent so unset values are left untouched.
+func (c *FifoClient) UpdateVmConfig(uuid string, config map[string]interface{}) error {
+	jsonDocument, _ := json.Marshal(map[string]interface{}{
+		"config": config,
+	})
+
+	_, err := c.SendRequest("PUT", "/api/3/vms/"+uuid, bytes.NewBuffer(jsonDocument))
+
+	return err
+}
+
+func (c *FifoClient) SetVmOwner(uuid string, org string) error {
+	jsonDocument, _ := json.Marshal(map[string]string{
+		"org": org,
+	})
+
+	_, err := c.SendRequest("PUT", "/api/3/vms/"+uuid+"/owner", bytes.NewBuffer(jsonDocument))
+
+	return err
+}
+
+func (c *FifoClient) ListHypervisors() ([]Hypervisor, error) {
+	response, err := c.SendRequest("GET", "/api/3/hypervisors", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var uuids []string
+	if err := json.Unmarshal(response, &uuids); err != nil {
+		return nil, err
+	}
+
+	hypervisors := make([]Hypervisor, 0, len(uuids))
+	for _, uuid := range uuids {
+		hv, err := c.GetHypervisor(uuid)
+		if err != nil {
+			return nil, err
+		}
+
+		hypervisors = append(hypervisors, hv)
+	}
+
+	return hypervisors, nil
+}
+
+func (c *FifoClient) CacheHypervisorList() error {
+	hypervisors, err := c.ListHypervisors()
+	if err != nil {
+		return err
+	}
+
+	for _, hv := range hypervisors {
+		c.HypervisorMap[hv.Alias] = hv.UUID
+	}
+
+	return nil
+}
+
+func (c *FifoClient) FindHypervisor(alias string) (Hypervisor, bool, error) {
+	if len(c.HypervisorMap) == 0 {
+		c.HypervisorMap = make(map[string]string)
+		err := c.CacheHypervisorList()
+		if err != nil {
+			return Hypervisor{}, false, err
+		}
+	}
+
+	uuid, found := c.HypervisorMap[alias]
+	if !found {
+		return Hypervisor{}, false, nil
+	}
+
+	foundHypervisor, err := c.GetHypervisor(uuid)
+
+	found = err == nil
+
+	return foundHypervisor, found, err
+}
+
+func (c *FifoClient) GetHypervisor(uuid string) (Hypervisor, error) {
+	response, err := c.SendRequest("GET", "/api/3/hypervisors/"+uuid, nil)
+	if err != nil {
+		return Hypervisor{}, err
+	}
+
+	hv := Hypervisor{}
+	if err := json.Unmarshal(response, &hv); err != nil {
+		return Hypervisor{}, err
+	}
+
+	return hv, nil
+}
+
+func (c *FifoClient) UpdateHypervisorConfig(uuid string, config map[string]interface{}) error {
+	jsonDocument, _ := json.Marshal(config)
+
+	_, err := c.SendRequest("PUT", "/api/3/hypervisors/"+uuid+"/config", bytes.NewBuffer(jsonDocument))
+
+	return err
+}
+
+func (c *FifoClient) SetHypervisorCharacteristic(uuid string, key string, value interface{}) error {
+	jsonDocument, _ := json.Marshal(map[string]interface{}{
+		key: value,
+	})
+
+	_, err := c.SendRequest("PUT", "/api/3/hypervisors/"+uuid+"/characteristics", bytes.NewBuffer(jsonDocument))
+
+	return err
+}
+
+func (c *FifoClient) DeleteHypervisorCharacteristic(uuid string, key string) error {
+	_, err := c.SendRequest("DELETE", "/api/3/hypervisors/"+uuid+"/characteristics/"+url.PathEscape(key), nil)
+
+	return err
+}
+
+// SetService enables, disables or clears an SMF service on a VM or
+// hypervisor. collection is the API collection, e.g. "vms".
+func (c *FifoClient) SetService(collection string, uuid string, fmri string, action string) error {
+	jsonDocument, _ := json.Marshal(map[string]string{
+		"action":  action,
+		"service": fmri,
+	})
+
+	_, err := c.SendRequest("PUT", "/api/3/"+collection+"/"+uuid+"/services", bytes.NewBuffer(jsonDocument))
+
+	return err
+}
+
+func metadataPath(collection string, uuid string, path []string) string {
+	api := "/api/3/" + collection + "/" + uuid + "/metadata"
+	for _, segment := range path {
+		api += "/" + url.PathEscape(segment)
+	}
+
+	return api
+}
+
+// SetMetadata merges values into the metadata of any FiFo object below the
+// given path. collection is the API collection, e.g. "vms".
+func (c *FifoClient) SetMetadata(collection string, uuid string, path []string, values map[string]interface{}) error {
+	jsonDocument, _ := json.Marshal(values)
+
+	_, err := c.SendRequest("PUT", metadataPath(collection, uuid, path), bytes.NewBuffer(jsonDocument))
+
+	return err
+}
+
+// StampMetadata marks a newly created object as managed by Terraform by
+// writing the provider's default metadata into the metadata namespace.
+func (c *FifoClient) StampMetadata(collection string, uuid string) error {
+	return c.SetMetadata(collection, uuid, []string{c.MetadataNamespace}, c.DefaultMetadata)
+}
+
+func (c *FifoClient) DeleteMetadata(collection string, uuid string, path []string) error {
+	_, err := c.SendRequest("DELETE", metadataPath(collection, uuid, path), nil)
+
+	return err
+}
+
+// createObject posts a new object to a collection and returns the uuid FiFo
+// assigned to it.
+func (c *FifoClient) createObject(api string, m interface{}) (string, error) {
+	jsonDocument, _ := json.Marshal(m)
+
+	response, err := c.SendRequest("POST", api, bytes.NewBuffer(jsonDocument))
+	if err != nil {
+		return "", err
+	}
+
+	result := make(map[string]interface{})
+	if err := json.Unmarshal(response, &result); err != nil {
+		return "", err
+	}
+
+	uuid, ok := result["uuid"].(string)
+	if !ok {
+		return "", fmt.Errorf("The response to POST %s did not include a uuid", api)
+	}
+
+	return uuid, nil
+}
+
+func permissionPath(collection string, uuid string, path []string) string {
+	api := "/api/3/" + collection + "/" + uuid + "/permissions"
+	for _, segment := range path {
+		api += "/" + url.PathEscape(segment)
+	}
+
+	return api
+}
+
+// GrantPermission grants a permission path to a user or role. collection is
+// either "users" or "roles".
+func (c *FifoClient) GrantPermission(collection string, uuid string, path []string) error {
+	_, err := c.SendRequest("PUT", permissionPath(collection, uuid, path), nil)
+
+	return err
+}
+
+func (c *FifoClient) RevokePermission(collection string, uuid string, path []string) error {
+	_, err := c.SendRequest("DELETE", permissionPath(collection, uuid, path), nil)
+
+	return err
+}
+
+func (c *FifoClient) CreateUser(name string, password string) (string, error) {
+	return c.createObject("/api/3/users", map[string]string{
+		"user":     name,
+		"password": password,
+	})
+}
+
+func (c *FifoClient) SetUserPassword(uuid string, password string) error {
+	jsonDocument, _ := json.Marshal(map[string]string{
+		"password": password,
+	})
+
+	_, err := c.SendRequest("PUT", "/api/3/users/"+uuid, bytes.NewBuffer(jsonDocument))
+
+	return err
+}
+
+func (c *FifoClient) DeleteUser(uuid string) error {
+	_, err := c.SendRequest("DELETE", "/api/3/users/"+uuid, nil)
+
+	return err
+}
+
+func (c *FifoClient) AddUserKey(uuid string, name string, key string) error {
+	jsonDocument, _ := json.Marshal(map[string]string{
+		name: key,
+	})
+
+	_, err := c.SendRequest("PUT", "/api/3/users/"+uuid+"/keys", bytes.NewBuffer(jsonDocument))
+
+	return err
+}
+
+func (c *FifoClient) DeleteUserKey(uuid string, name string) error {
+	_, err := c.SendRequest("DELETE", "/api/3/users/"+uuid+"/keys/"+url.PathEscape(name), nil)
+
+	return err
+}
+
+func (c *FifoClient) AddUserYubikey(uuid string, otp string) error {
+	jsonDocument, _ := json.Marshal(map[string]string{
+		"otp": otp,
+	})
+
+	_, err := c.SendRequest("PUT", "/api/3/users/"+uuid+"/yubikeys", bytes.NewBuffer(jsonDocument))
+
+	return err
+}
+
+func (c *FifoClient) DeleteUserYubikey(uuid string, id string) error {
+	_, err := c.SendRequest("DELETE", "/api/3/users/"+uuid+"/yubikeys/"+url.PathEscape(id), nil)
+
+	return err
+}
+
+func (c *FifoClient) AddUserRole(uuid string, role string) error {
+	_, err := c.SendRequest("PUT", "/api/3/users/"+uuid+"/roles/"+role, nil)
+
+	return err
+}
+
+func (c *FifoClient) RemoveUserRole(uuid string, role string) error {
+	_, err := c.SendRequest("DELETE", "/api/3/users/"+uuid+"/roles/"+role, nil)
+
+	return err
+}
+
+// SetUserActiveOrg joins the user to an org and makes it the active one.
+func (c *FifoClient) SetUserActiveOrg(uuid string, org string) error {
+	jsonDocument, _ := json.Marshal(map[string]bool{
+		"active": true,
+	})
+
+	_, err := c.SendRequest("PUT", "/api/3/users/"+uuid+"/orgs/"+org, bytes.NewBuffer(jsonDocument))
+
+	return err
+}
+
+func (c *FifoClient) CreateRole(name string) (string, error) {
+	return c.createObject("/api/3/roles", map[string]string{
+		"name": name,
+	})
+}
+
+func (c *FifoClient) GetRole(uuid string) (Role, error) {
+	role := Role{}
+
+	response, err := c.SendRequest("GET", "/api/3/roles/"+uuid, nil)
+	if err != nil {
+		return role, err
+	}
+
+	if err := json.Unmarshal(response, &role); err != nil {
+		return role, err
+	}
+
+	return role, nil
+}
+
+func (c *FifoClient) DeleteRole(uuid string) error {
+	_, err := c.SendRequest("DELETE", "/api/3/roles/"+uuid, nil)
+
+	return err
+}
+
+func (c *FifoClient) CreateOrg(name string) (string, error) {
+	return c.createObject("/api/3/orgs", map[string]string{
+		"name": name,
+	})
+}
+
+func (c *FifoClient) GetOrg(uuid string) (Org, error) {
+	org := Org{}
+
+	response, err := c.SendRequest("GET", "/api/3/orgs/"+uuid, nil)
+	if err != nil {
+		return org, err
+	}
+
+	if err := json.Unmarshal(response, &org); err != nil {
+		return org, err
+	}
+
+	return org, nil
+}
+
+func (c *FifoClient) DeleteOrg(uuid string) error {
+	_, err := c.SendRequest("DELETE", "/api/3/orgs/"+uuid, nil)
+
+	return err
+}
+
+func (c *FifoClient) AddOrgTrigger(uuid string, event string, trigger *OrgTrigger) error {
+	jsonDocument, _ := json.Marshal(trigger)
+
+	_, err := c.SendRequest("POST", "/api/3/orgs/"+uuid+"/triggers/"+event, bytes.NewBuffer(jsonDocument))
+
+	return err
+}
+
+func (c *FifoClient) DeleteOrgTrigger(uuid string, triggerID string) error {
+	_, err := c.SendRequest("DELETE", "/api/3/orgs/"+uuid+"/triggers/"+triggerID, nil)
+
+	return err
+}
+
+func (c *FifoClient) CreateUserToken(uuid string, comment string, scope []string) (APIToken, error) {
+	token := APIToken{}
+
+	jsonDocument, _ := json.Marshal(map[string]interface{}{
+		"comment": comment,
+		"scope":   scope,
+	})
+
+	response, err := c.SendRequest("POST", "/api/3/users/"+uuid+"/tokens", bytes.NewBuffer(jsonDocument))
+	if err != nil {
+		return token, err
+	}
+
+	if err := json.Unmarshal(response, &token); err != nil {
+		return token, err
+	}
+
+	return token, nil
+}
+
+func (c *FifoClient) DeleteUserToken(uuid string, tokenID string) error {
+	_, err := c.SendRequest("DELETE", "/api/3/users/"+uuid+"/tokens/"+tokenID, nil)
+
+	return err
+}
+
+func (c *FifoClient) CreateGrouping(name string, groupingType string) (string, error) {
+	return c.createObject("/api/3/groupings", map[string]string{
+		"name": name,
+		"type": groupingType,
+	})
+}
+
+func (c *FifoClient) GetGrouping(uuid string) (Grouping, error) {
+	grouping := Grouping{}
+
+	response, err := c.SendRequest("GET", "/api/3/groupings/"+uuid, nil)
+	if err != nil {
+		return grouping, err
+	}
+
+	if err := json.Unmarshal(response, &grouping); err != nil {
+		return grouping, err
+	}
+
+	return grouping, nil
+}
+
+func (c *FifoClient) DeleteGrouping(uuid string) error {
+	_, err := c.SendRequest("DELETE", "/api/3/groupings/"+uuid, nil)
+
+	return err
+}
+
+// AddGroupingElement adds a VM to a cluster or a cluster to a stack.
+func (c *FifoClient) AddGroupingElement(uuid string, element string) error {
+	_, err := c.SendRequest("PUT", "/api/3/groupings/"+uuid+"/elements/"+element, nil)
+
+	return err
+}
+
+func (c *FifoClient) RemoveGroupingElement(uuid string, element string) error {
+	_, err := c.SendRequest("DELETE", "/api/3/groupings/"+uuid+"/elements/"+element, nil)
+
+	return err
+}
+
+func (c *FifoClient) SetGroupingConfig(uuid string, config map[string]interface{}) error {
+	jsonDocument, _ := json.Marshal(config)
+
+	_, err := c.SendRequest("PUT", "/api/3/groupings/"+uuid+"/config", bytes.NewBuffer(jsonDocument))
+
+	return err
+}
+
+func (c *FifoClient) DeleteGroupingConfig(uuid string, key string) error {
+	_, err := c.SendRequest("DELETE", "/api/3/groupings/"+uuid+"/config/"+url.PathEscape(key), nil)
+
+	return err
+}
+
+func (c *FifoClient) CreateDTrace(m *DTrace) (string, error) {
+	return c.createObject("/api/3/dtrace", m)
+}
+
+func (c *FifoClient) CacheDTraceList() error {
+	response, err := c.SendRequest("GET", "/api/3/dtrace", nil)
+	if err != nil {
+		return err
+	}
+
+	var scripts []string
+	if err := json.Unmarshal(response, &scripts); err != nil {
+		return err
+	}
+
+	for _, uuid := range scripts {
+		script, err := c.GetDTrace(uuid)
+		if err != nil {
+			return err
+		}
+
+		c.DTraceMap[script.Name] = uuid
+	}
+
+	return nil
+}
+
+func (c *FifoClient) FindDTrace(name string) (DTrace, bool, error) {
+	if len(c.DTraceMap) == 0 {
+		c.DTraceMap = make(map[string]string)
+		err := c.CacheDTraceList()
+		if err != nil {
+			return DTrace{}, false, err
+		}
+	}
+
+	uuid, found := c.DTraceMap[name]
+	if !found {
+		return DTrace{}, false, nil
+	}
+
+	foundScript, err := c.GetDTrace(uuid)
+
+	found = err == nil
+
+	return foundScript, found, err
+}
+
+func (c *FifoClient) GetDTrace(uuid string) (DTrace, error) {
+	script := DTrace{}
+
+	response, err := c.SendRequest("GET", "/api/3/dtrace/"+uuid, nil)
+	if err != nil {
+		return script, err
+	}
+
+	if err := json.Unmarshal(response, &script); err != nil {
+		return script, err
+	}
+
+	return script, nil
+}
+
+func (c *FifoClient) UpdateDTrace(uuid string, m *DTrace) error {
+	jsonDocument, _ := json.Marshal(m)
+
+	_, err := c.SendRequest("PUT", "/api/3/dtrace/"+uuid, bytes.NewBuffer(jsonDocument))
+
+	return err
+}
+
+func (c *FifoClient) DeleteDTrace(uuid string) error {
+	_, err := c.SendRequest("DELETE", "/api/3/dtrace/"+uuid, nil)
+
+	return err
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+)
+
+func ipToUint32(ip string) (uint32, error) {
+	parsed := net.ParseIP(ip).To4()
+	if parsed == nil {
+		return 0, fmt.Errorf("%s is not a valid IPv4 address", ip)
+	}
+
+	return uint32(parsed[0])<<24 | uint32(parsed[1])<<16 | uint32(parsed[2])<<8 | uint32(parsed[3]), nil
+}
+
+func uint32ToIP(n uint32) string {
+	return net.IPv4(byte(n>>24), byte(n>>16), byte(n>>8), byte(n)).String()
+}
+
+// IPList is a list of IPv4 addresses. FiFo may report addresses either as
+// dotted strings or as their integer value.
+type IPList []string
+
+func (l *IPList) UnmarshalJSON(data []byte) error {
+	var values []interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	ips := make(IPList, 0, len(values))
+	for _, value := range values {
+		switch v := value.(type) {
+		case string:
+			ips = append(ips, v)
+		case float64:
+			ips = append(ips, uint32ToIP(uint32(v)))
+		default:
+			return fmt.Errorf("Unexpected IP address %v", value)
+		}
+	}
+
+	*l = ips
+
+	return nil
+}
@@ -43,6 +43,46 @@ func providerSchema() map[string]*schema.Schema {
 				"TF_FIFO_ENDPOINT",
 			}, nil),
 		},
+		"default_org": &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The organization new VMs are assigned to when they don't set an owner",
+			DefaultFunc: schema.MultiEnvDefaultFunc([]string{
+				"TF_FIFO_DEFAULT_ORG",
+			}, nil),
+		},
+		"default_tags": &schema.Schema{
+			Type:        schema.TypeMap,
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "Tags merged into the tags of every VM",
+		},
+		"default_metadata": &schema.Schema{
+			Type:        schema.TypeList,
+			Optional:    true,
+			MaxItems:    1,
+			Description: "Metadata stamped on every object the provider creates, next to managed_by = \"terraform\"",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"workspace": &schema.Schema{
+						Type:        schema.TypeString,
+						Required:    true,
+						Description: "Terraform doesn't tell providers the current workspace, set it to \"${terraform.workspace}\"",
+					},
+					"metadata": &schema.Schema{
+						Type:     schema.TypeMap,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+				},
+			},
+		},
+		"metadata_namespace": &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			Default:     "terraform",
+			Description: "The metadata key VM tags and default metadata are stored under",
+		},
 	}
 }
 
@@ -51,9 +91,24 @@ func providerSchema() map[string]*schema.Schema {
 // interacts with the Project Fifo API.
 func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 	client := FifoClient{
-		ApiKey:   d.Get("api_key").(string),
-		Endpoint: d.Get("endpoint").(string),
+		ApiKey:     d.Get("api_key").(string),
+		Endpoint:   d.Get("endpoint").(string),
+		DefaultOrg: d.Get("default_org").(string),
+
+		DefaultTags:       d.Get("default_tags").(map[string]interface{}),
+		MetadataNamespace: d.Get("metadata_namespace").(string),
+	}
+
+	client.DefaultMetadata = map[string]interface{}{}
+	if defaults := d.Get("default_metadata").([]interface{}); len(defaults) > 0 && defaults[0] != nil {
+		cfg := defaults[0].(map[string]interface{})
+		for key, value := range cfg["metadata"].(map[string]interface{}) {
+			client.DefaultMetadata[key] = value
+		}
+		client.DefaultMetadata["workspace"] = cfg["workspace"].(string)
 	}
+	// Set last so the configured metadata can't override it.
+	client.DefaultMetadata["managed_by"] = "terraform"
 
 	// You could have some field validations here, like checking that
 	// the API Key is has not expired or that the username/password
@@ -64,15 +119,30 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 
 func providerResources() map[string]*schema.Resource {
 	return map[string]*schema.Resource{
-		"projectfifo_vm": resourceVm(),
+		"projectfifo_vm":           resourceVm(),
+		"projectfifo_hypervisor":   resourceHypervisor(),
+		"projectfifo_user":         resourceUser(),
+		"projectfifo_user_ssh_key": resourceUserSSHKey(),
+		"projectfifo_role":         resourceRole(),
+		"projectfifo_org":          resourceOrg(),
+		"projectfifo_permission":   resourcePermission(),
+		"projectfifo_api_token":    resourceAPIToken(),
+		"projectfifo_dtrace":       resourceDTrace(),
+		"projectfifo_grouping":     resourceGrouping(),
 	}
 }
 
 func providerDataSources() map[string]*schema.Resource {
 	return map[string]*schema.Resource{
-		"projectfifo_iprange": datasourceIpRange(),
-		"projectfifo_package": datasourcePackage(),
-		"projectfifo_network": datasourceNetwork(),
-		"projectfifo_dataset": datasourceDataset(),
+		"projectfifo_iprange":         datasourceIpRange(),
+		"projectfifo_iprange_free_ip": datasourceIpRangeFreeIp(),
+		"projectfifo_package":         datasourcePackage(),
+		"projectfifo_network":         datasourceNetwork(),
+		"projectfifo_dataset":         datasourceDataset(),
+		"projectfifo_hypervisor":      datasourceHypervisor(),
+		"projectfifo_hypervisors":     datasourceHypervisors(),
+		"projectfifo_dtrace":          datasourceDTrace(),
+		"projectfifo_vm":              datasourceVm(),
+		"projectfifo_vms":             datasourceVms(),
 	}
 }
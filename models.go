@@ -9,6 +9,8 @@ type IPRange struct {
 	Vlan    int    `json:"vlan"`
 	First   string `json:"first"`
 	Last    string `json:"last"`
+	Free    IPList `json:"free,omitempty"`
+	Used    IPList `json:"used,omitempty"`
 	UUID    string `json:"uuid"`
 }
 
@@ -20,23 +22,143 @@ type Package struct {
 type Dataset struct {
 	Name    string `json:"name"`
 	Version string `json:"version"`
+	OS      string `json:"os"`
+	Type    string `json:"type"`
 	UUID    string `json:"uuid"`
 }
 
 type Network struct {
-	Name string `json:"name"`
-	UUID string `json:"uuid"`
+	Name     string   `json:"name"`
+	IPRanges []string `json:"ipranges"`
+	UUID     string   `json:"uuid"`
+}
+
+type UserToken struct {
+	ID      string   `json:"id"`
+	Comment string   `json:"comment"`
+	Scope   []string `json:"scope"`
+}
+
+// APIToken is returned once when a token is created, FiFo never reveals the
+// secret again.
+type APIToken struct {
+	ID    string `json:"token-id"`
+	Token string `json:"token"`
+}
+
+type User struct {
+	Name        string                 `json:"name"`
+	UUID        string                 `json:"uuid"`
+	Keys        map[string]string      `json:"keys"`
+	Yubikeys    []string               `json:"yubikeys"`
+	Roles       []string               `json:"roles"`
+	Permissions [][]string             `json:"permissions"`
+	Orgs        []string               `json:"orgs"`
+	Org         string                 `json:"org"`
+	Tokens      []UserToken            `json:"tokens"`
+	Metadata    map[string]interface{} `json:"metadata"`
+}
+
+type HypervisorResources struct {
+	TotalMemory       int `json:"total-memory"`
+	ProvisionedMemory int `json:"provisioned-memory"`
+	FreeMemory        int `json:"free-memory"`
+}
+
+type Hypervisor struct {
+	Alias           string                 `json:"alias"`
+	Host            string                 `json:"host"`
+	Characteristics map[string]interface{} `json:"characteristics"`
+	Resources       HypervisorResources    `json:"resources"`
+	Pools           map[string]interface{} `json:"pools"`
+	Sysinfo         map[string]interface{} `json:"sysinfo"`
+	Version         string                 `json:"version"`
+	Virtualisation  []string               `json:"virtualisation"`
+	Metadata        map[string]interface{} `json:"metadata"`
+	Services        map[string]string      `json:"services"`
+	UUID            string                 `json:"uuid"`
+}
+
+type Role struct {
+	Name        string                 `json:"name"`
+	UUID        string                 `json:"uuid"`
+	Permissions [][]string             `json:"permissions"`
+	Metadata    map[string]interface{} `json:"metadata"`
+}
+
+type OrgTrigger struct {
+	Trigger    string   `json:"trigger,omitempty"`
+	Action     string   `json:"action"`
+	Base       string   `json:"base,omitempty"`
+	Permission []string `json:"permission,omitempty"`
+	Target     string   `json:"target"`
+}
+
+type Org struct {
+	Name     string                 `json:"name"`
+	UUID     string                 `json:"uuid"`
+	Triggers map[string]OrgTrigger  `json:"triggers"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+type Grouping struct {
+	Name      string                 `json:"name"`
+	Type      string                 `json:"type"`
+	Elements  []string               `json:"elements"`
+	Groupings []string               `json:"groupings"`
+	Config    map[string]interface{} `json:"config"`
+	Metadata  map[string]interface{} `json:"metadata"`
+	UUID      string                 `json:"uuid"`
+}
+
+type DTrace struct {
+	Name   string                 `json:"name"`
+	Script string                 `json:"script"`
+	Config map[string]interface{} `json:"config"`
+	UUID   string                 `json:"uuid,omitempty"`
 }
 
 type VMNetworkConfigCreate struct {
 	Net0 string `json:"net0"`
 }
 
+type VMDisk struct {
+	Size  int    `json:"size"`
+	Model string `json:"model,omitempty"`
+	Boot  bool   `json:"boot,omitempty"`
+}
+
+// VMRequirement is a placement rule evaluated against hypervisors. Weight is
+// either "must", "cant" or a numeric preference score.
+type VMRequirement struct {
+	Weight    interface{} `json:"weight"`
+	Attribute string      `json:"attribute"`
+	Condition string      `json:"condition"`
+	Value     interface{} `json:"value"`
+}
+
 type VMConfigCreate struct {
 	Alias    string                `json:"alias"`
 	Autoboot bool                  `json:"autoboot"`
 	Hostname string                `json:"hostname"`
 	Networks VMNetworkConfigCreate `json:"networks"`
+	IPs      map[string]string     `json:"ips,omitempty"`
+	SSHKeys  string                `json:"ssh_keys,omitempty"`
+
+	Resolvers         []string          `json:"resolvers,omitempty"`
+	Routes            map[string]string `json:"routes,omitempty"`
+	DNSDomain         string            `json:"dns_domain,omitempty"`
+	MaintainResolvers bool              `json:"maintain_resolvers,omitempty"`
+
+	Disks       []VMDisk `json:"disks,omitempty"`
+	NicDriver   string   `json:"nic_driver,omitempty"`
+	DiskDriver  string   `json:"disk_driver,omitempty"`
+	CPUType     string   `json:"cpu_type,omitempty"`
+	VNCPort     int      `json:"vnc_port,omitempty"`
+	VNCPassword string   `json:"vnc_password,omitempty"`
+
+	Requirements []VMRequirement `json:"requirements,omitempty"`
+	Grouping     string          `json:"grouping,omitempty"`
 }
 
 type VMCreate struct {
@@ -57,12 +179,32 @@ type VMConfig struct {
 	Autoboot bool              `json:"autoboot"`
 	Hostname string            `json:"hostname"`
 	Networks []VMNetworkConfig `json:"networks"`
+
+	Resolvers         []string          `json:"resolvers"`
+	Routes            map[string]string `json:"routes"`
+	DNSDomain         string            `json:"dns_domain"`
+	MaintainResolvers bool              `json:"maintain_resolvers"`
+}
+
+type VMFwRule struct {
+	ID        int         `json:"id,omitempty"`
+	Action    string      `json:"action"`
+	Direction string      `json:"direction"`
+	Protocol  string      `json:"protocol"`
+	Target    interface{} `json:"target"`
+	Filters   interface{} `json:"filters,omitempty"`
 }
 
 type VM struct {
-	Dataset string   `json:"dataset"`
-	Package string   `json:"package"`
-	Config  VMConfig `json:"config"`
-	UUID    string   `json:"uuid"`
-	State   string   `json:"state"`
+	Dataset    string                 `json:"dataset"`
+	Package    string                 `json:"package"`
+	Config     VMConfig               `json:"config"`
+	FwRules    []VMFwRule             `json:"fw_rules"`
+	Hypervisor string                 `json:"hypervisor"`
+	Owner      string                 `json:"owner"`
+	Groupings  []string               `json:"groupings"`
+	Services   map[string]string      `json:"services"`
+	Metadata   map[string]interface{} `json:"metadata"`
+	UUID       string                 `json:"uuid"`
+	State      string                 `json:"state"`
 }
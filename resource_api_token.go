@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceAPIToken() *schema.Resource {
+	return &schema.Resource{
+		SchemaVersion: 1,
+		Create:        apiTokenCreateFunc,
+		Read:          apiTokenReadFunc,
+		Delete:        apiTokenDeleteFunc,
+		Schema: map[string]*schema.Schema{
+			"user": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"comment": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"scope": &schema.Schema{
+				Type:     schema.TypeList,
+				Required: true,
+				ForceNew: true,
+				MinItems: 1,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"token": &schema.Schema{
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+		},
+	}
+}
+
+func apiTokenCreateFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	scope := make([]string, 0)
+	for _, s := range d.Get("scope").([]interface{}) {
+		scope = append(scope, s.(string))
+	}
+
+	token, err := client.CreateUserToken(d.Get("user").(string), d.Get("comment").(string), scope)
+	if err != nil {
+		return err
+	}
+
+	if token.ID == "" {
+		return fmt.Errorf("FiFo did not return an id for the new API token")
+	}
+
+	// The secret is only available now, it is kept in state from here on.
+	d.SetId(token.ID)
+	d.Set("token", token.Token)
+
+	return apiTokenReadFunc(d, meta)
+}
+
+func apiTokenReadFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	user, err := client.GetUser(d.Get("user").(string))
+	if err != nil {
+		return err
+	}
+
+	for _, token := range user.Tokens {
+		if token.ID == d.Id() {
+			return nil
+		}
+	}
+
+	// The token was revoked outside Terraform.
+	d.SetId("")
+
+	return nil
+}
+
+func apiTokenDeleteFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	return client.DeleteUserToken(d.Get("user").(string), d.Id())
+}
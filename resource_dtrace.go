@@ -0,0 +1,89 @@
+package main
+
+import "github.com/hashicorp/terraform/helper/schema"
+
+func resourceDTrace() *schema.Resource {
+	return &schema.Resource{
+		SchemaVersion: 1,
+		Create:        dtraceCreateFunc,
+		Read:          dtraceReadFunc,
+		Update:        dtraceUpdateFunc,
+		Delete:        dtraceDeleteFunc,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"script": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"config": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func getDTrace(d *schema.ResourceData) DTrace {
+	return DTrace{
+		Name:   d.Get("name").(string),
+		Script: d.Get("script").(string),
+		Config: d.Get("config").(map[string]interface{}),
+	}
+}
+
+func dtraceCreateFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	script := getDTrace(d)
+	id, err := client.CreateDTrace(&script)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(id)
+
+	if err := client.StampMetadata("dtrace", id); err != nil {
+		return err
+	}
+
+	return dtraceReadFunc(d, meta)
+}
+
+func dtraceReadFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	script, err := client.GetDTrace(d.Id())
+	if err != nil {
+		return err
+	}
+
+	d.Set("name", script.Name)
+	d.Set("script", script.Script)
+	d.Set("config", flattenMetadata(script.Config))
+
+	return nil
+}
+
+func dtraceUpdateFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	script := getDTrace(d)
+	if err := client.UpdateDTrace(d.Id(), &script); err != nil {
+		return err
+	}
+
+	return dtraceReadFunc(d, meta)
+}
+
+func dtraceDeleteFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	return client.DeleteDTrace(d.Id())
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+// resourceGrouping manages clusters of VMs and stacks of clusters. Members
+// may also be added from projectfifo_vm, so only members listed here are
+// tracked and members added elsewhere are left alone.
+func resourceGrouping() *schema.Resource {
+	return &schema.Resource{
+		SchemaVersion: 1,
+		Create:        groupingCreateFunc,
+		Read:          groupingReadFunc,
+		Update:        groupingUpdateFunc,
+		Delete:        groupingDeleteFunc,
+		CustomizeDiff: groupingCustomizeDiffFunc,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"type": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"cluster", "stack"}, false),
+			},
+			"config": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"vms": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
+			},
+			"groupings": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
+			},
+		},
+	}
+}
+
+func updateGroupingElements(client *FifoClient, uuid string, o, n *schema.Set) error {
+	for _, element := range o.Difference(n).List() {
+		if err := client.RemoveGroupingElement(uuid, element.(string)); err != nil {
+			return err
+		}
+	}
+
+	for _, element := range n.Difference(o).List() {
+		if err := client.AddGroupingElement(uuid, element.(string)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func updateGroupingConfig(client *FifoClient, uuid string, o, n map[string]interface{}) error {
+	removed, changed := diffStringMaps(o, n)
+	for _, key := range removed {
+		if err := client.DeleteGroupingConfig(uuid, key); err != nil {
+			return err
+		}
+	}
+
+	if len(changed) > 0 {
+		return client.SetGroupingConfig(uuid, changed)
+	}
+
+	return nil
+}
+
+func groupingCustomizeDiffFunc(d *schema.ResourceDiff, meta interface{}) error {
+	switch d.Get("type").(string) {
+	case "cluster":
+		if d.Get("groupings").(*schema.Set).Len() > 0 {
+			return fmt.Errorf("Only stacks can contain groupings, use vms for clusters")
+		}
+	case "stack":
+		if d.Get("vms").(*schema.Set).Len() > 0 {
+			return fmt.Errorf("Only clusters can contain VMs, use groupings for stacks")
+		}
+	}
+
+	return nil
+}
+
+func groupingCreateFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	id, err := client.CreateGrouping(d.Get("name").(string), d.Get("type").(string))
+	if err != nil {
+		return err
+	}
+
+	d.SetId(id)
+
+	if err := client.StampMetadata("groupings", id); err != nil {
+		return err
+	}
+
+	if err := updateGroupingConfig(client, id, map[string]interface{}{}, d.Get("config").(map[string]interface{})); err != nil {
+		return err
+	}
+
+	empty := schema.NewSet(schema.HashString, nil)
+	if err := updateGroupingElements(client, id, empty, d.Get("vms").(*schema.Set)); err != nil {
+		return err
+	}
+
+	if err := updateGroupingElements(client, id, empty, d.Get("groupings").(*schema.Set)); err != nil {
+		return err
+	}
+
+	return groupingReadFunc(d, meta)
+}
+
+func groupingReadFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	grouping, err := client.GetGrouping(d.Id())
+	if err != nil {
+		return err
+	}
+
+	// Stacks list their clusters as elements as well, depending on the
+	// FiFo version, so members are looked up in both lists.
+	members := append(append([]string{}, grouping.Elements...), grouping.Groupings...)
+
+	d.Set("name", grouping.Name)
+	d.Set("type", grouping.Type)
+	d.Set("config", getManagedValues(d.Get("config").(map[string]interface{}), grouping.Config))
+	d.Set("vms", getManagedMembers(d.Get("vms").(*schema.Set), members))
+	d.Set("groupings", getManagedMembers(d.Get("groupings").(*schema.Set), members))
+
+	return nil
+}
+
+func groupingUpdateFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	if d.HasChange("config") {
+		o, n := d.GetChange("config")
+		if err := updateGroupingConfig(client, d.Id(), o.(map[string]interface{}), n.(map[string]interface{})); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range []string{"vms", "groupings"} {
+		if d.HasChange(key) {
+			o, n := d.GetChange(key)
+			if err := updateGroupingElements(client, d.Id(), o.(*schema.Set), n.(*schema.Set)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return groupingReadFunc(d, meta)
+}
+
+func groupingDeleteFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	return client.DeleteGrouping(d.Id())
+}
@@ -0,0 +1,161 @@
+package main
+
+import "github.com/hashicorp/terraform/helper/schema"
+
+// resourceHypervisor adopts an existing hypervisor. Hardware is never
+// created or destroyed, only the settings listed in the schema are managed.
+func resourceHypervisor() *schema.Resource {
+	return &schema.Resource{
+		SchemaVersion: 1,
+		Create:        hypervisorCreateFunc,
+		Read:          hypervisorReadFunc,
+		Update:        hypervisorUpdateFunc,
+		Delete:        hypervisorDeleteFunc,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		Schema: map[string]*schema.Schema{
+			"uuid": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"alias": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"characteristics": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"metadata": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"services": servicesSchema(),
+		},
+	}
+}
+
+func updateHypervisorCharacteristics(client *FifoClient, uuid string, o, n map[string]interface{}) error {
+	removed, changed := diffStringMaps(o, n)
+	for _, key := range removed {
+		if err := client.DeleteHypervisorCharacteristic(uuid, key); err != nil {
+			return err
+		}
+	}
+
+	for key, value := range changed {
+		if err := client.SetHypervisorCharacteristic(uuid, key, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func hypervisorCreateFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	uuid := d.Get("uuid").(string)
+	if _, err := client.GetHypervisor(uuid); err != nil {
+		return err
+	}
+
+	if alias := d.Get("alias").(string); alias != "" {
+		if err := client.UpdateHypervisorConfig(uuid, map[string]interface{}{"alias": alias}); err != nil {
+			return err
+		}
+	}
+
+	empty := map[string]interface{}{}
+	if err := updateHypervisorCharacteristics(client, uuid, empty, d.Get("characteristics").(map[string]interface{})); err != nil {
+		return err
+	}
+
+	if err := updateMetadata(client, "hypervisors", uuid, nil, empty, d.Get("metadata").(map[string]interface{})); err != nil {
+		return err
+	}
+
+	if err := setServices(client, "hypervisors", uuid, d.Get("services").(map[string]interface{})); err != nil {
+		return err
+	}
+
+	d.SetId(uuid)
+
+	return hypervisorReadFunc(d, meta)
+}
+
+func hypervisorReadFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	hv, err := client.GetHypervisor(d.Id())
+	if err != nil {
+		return err
+	}
+
+	d.Set("uuid", hv.UUID)
+	d.Set("alias", hv.Alias)
+	d.Set("characteristics", getManagedValues(d.Get("characteristics").(map[string]interface{}), hv.Characteristics))
+	d.Set("metadata", getManagedValues(d.Get("metadata").(map[string]interface{}), hv.Metadata))
+	d.Set("services", getManagedServices(d.Get("services").(map[string]interface{}), hv.Services))
+
+	return nil
+}
+
+func hypervisorUpdateFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	if d.HasChange("alias") {
+		if err := client.UpdateHypervisorConfig(d.Id(), map[string]interface{}{"alias": d.Get("alias").(string)}); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("characteristics") {
+		o, n := d.GetChange("characteristics")
+		if err := updateHypervisorCharacteristics(client, d.Id(), o.(map[string]interface{}), n.(map[string]interface{})); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("metadata") {
+		o, n := d.GetChange("metadata")
+		if err := updateMetadata(client, "hypervisors", d.Id(), nil, o.(map[string]interface{}), n.(map[string]interface{})); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("services") {
+		o, n := d.GetChange("services")
+		_, changed := diffStringMaps(o.(map[string]interface{}), n.(map[string]interface{}))
+		if err := setServices(client, "hypervisors", d.Id(), changed); err != nil {
+			return err
+		}
+	}
+
+	return hypervisorReadFunc(d, meta)
+}
+
+// hypervisorDeleteFunc releases the hypervisor from Terraform, removing the
+// characteristics and metadata keys it managed. The hypervisor itself is
+// left untouched.
+func hypervisorDeleteFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	empty := map[string]interface{}{}
+	if err := updateHypervisorCharacteristics(client, d.Id(), d.Get("characteristics").(map[string]interface{}), empty); err != nil {
+		return err
+	}
+
+	if err := updateMetadata(client, "hypervisors", d.Id(), nil, d.Get("metadata").(map[string]interface{}), empty); err != nil {
+		return err
+	}
+
+	d.SetId("")
+
+	return nil
+}
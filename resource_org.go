@@ -0,0 +1,254 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+// orgTriggerBases maps trigger events to the collection the new object
+// belongs to, which is the base of the permissions a trigger grants.
+var orgTriggerBases = map[string]string{
+	"vm_create":      "vms",
+	"user_create":    "users",
+	"dataset_create": "datasets",
+}
+
+func resourceOrg() *schema.Resource {
+	return &schema.Resource{
+		SchemaVersion: 1,
+		Create:        orgCreateFunc,
+		Read:          orgReadFunc,
+		Update:        orgUpdateFunc,
+		Delete:        orgDeleteFunc,
+		CustomizeDiff: orgCustomizeDiffFunc,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"metadata": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"trigger": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"event": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice([]string{"vm_create", "user_create", "dataset_create"}, false),
+						},
+						"action": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice([]string{"role_grant", "user_grant", "join_role", "join_org"}, false),
+						},
+						"target": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"permission": &schema.Schema{
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type:         schema.TypeString,
+								ValidateFunc: validatePermissionSegment,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// orgCustomizeDiffFunc checks that exactly the grant actions carry a
+// permission.
+func orgCustomizeDiffFunc(d *schema.ResourceDiff, meta interface{}) error {
+	for _, t := range d.Get("trigger").(*schema.Set).List() {
+		trigger := t.(map[string]interface{})
+		action := trigger["action"].(string)
+		permission := trigger["permission"].([]interface{})
+
+		grant := strings.HasSuffix(action, "_grant")
+		if grant && len(permission) == 0 {
+			return fmt.Errorf("Trigger %s on %s requires a permission", action, trigger["event"].(string))
+		}
+
+		if !grant && len(permission) > 0 {
+			return fmt.Errorf("Trigger %s on %s can't set a permission, only grant actions can", action, trigger["event"].(string))
+		}
+	}
+
+	return nil
+}
+
+func getOrgTrigger(t map[string]interface{}) OrgTrigger {
+	trigger := OrgTrigger{
+		Trigger: t["event"].(string),
+		Action:  t["action"].(string),
+		Target:  t["target"].(string),
+	}
+
+	// Only grants carry a permission, joins just add the new object to the
+	// target role or org.
+	if strings.HasSuffix(trigger.Action, "_grant") {
+		trigger.Base = orgTriggerBases[trigger.Trigger]
+		for _, segment := range t["permission"].([]interface{}) {
+			trigger.Permission = append(trigger.Permission, segment.(string))
+		}
+	}
+
+	return trigger
+}
+
+func flattenOrgTrigger(trigger OrgTrigger) map[string]interface{} {
+	return map[string]interface{}{
+		"event":      trigger.Trigger,
+		"action":     trigger.Action,
+		"target":     trigger.Target,
+		"permission": trigger.Permission,
+	}
+}
+
+// orgTriggerKey builds a comparable key for a trigger so triggers in the
+// configuration can be matched against the ids FiFo assigned to them.
+func orgTriggerKey(trigger OrgTrigger) string {
+	return strings.Join([]string{
+		trigger.Trigger,
+		trigger.Action,
+		trigger.Target,
+		strings.Join(trigger.Permission, "/"),
+	}, "|")
+}
+
+func addOrgTriggers(client *FifoClient, uuid string, triggers []interface{}) error {
+	for _, t := range triggers {
+		trigger := getOrgTrigger(t.(map[string]interface{}))
+		event := trigger.Trigger
+		trigger.Trigger = ""
+
+		if err := client.AddOrgTrigger(uuid, event, &trigger); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func removeOrgTriggers(client *FifoClient, uuid string, triggers []interface{}) error {
+	if len(triggers) == 0 {
+		return nil
+	}
+
+	org, err := client.GetOrg(uuid)
+	if err != nil {
+		return err
+	}
+
+	triggerIDs := make(map[string]string)
+	for id, trigger := range org.Triggers {
+		triggerIDs[orgTriggerKey(trigger)] = id
+	}
+
+	for _, t := range triggers {
+		id, found := triggerIDs[orgTriggerKey(getOrgTrigger(t.(map[string]interface{})))]
+		if !found {
+			continue
+		}
+
+		if err := client.DeleteOrgTrigger(uuid, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func orgCreateFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	id, err := client.CreateOrg(d.Get("name").(string))
+	if err != nil {
+		return err
+	}
+
+	d.SetId(id)
+
+	if err := client.StampMetadata("orgs", id); err != nil {
+		return err
+	}
+
+	if err := updateMetadata(client, "orgs", id, nil, map[string]interface{}{}, d.Get("metadata").(map[string]interface{})); err != nil {
+		return err
+	}
+
+	if err := addOrgTriggers(client, id, d.Get("trigger").(*schema.Set).List()); err != nil {
+		return err
+	}
+
+	return orgReadFunc(d, meta)
+}
+
+func orgReadFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	org, err := client.GetOrg(d.Id())
+	if err != nil {
+		return err
+	}
+
+	triggers := make([]interface{}, 0, len(org.Triggers))
+	for _, trigger := range org.Triggers {
+		triggers = append(triggers, flattenOrgTrigger(trigger))
+	}
+
+	d.Set("name", org.Name)
+	d.Set("metadata", getManagedValues(d.Get("metadata").(map[string]interface{}), org.Metadata))
+	d.Set("trigger", triggers)
+
+	return nil
+}
+
+func orgUpdateFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	if d.HasChange("metadata") {
+		o, n := d.GetChange("metadata")
+		if err := updateMetadata(client, "orgs", d.Id(), nil, o.(map[string]interface{}), n.(map[string]interface{})); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("trigger") {
+		o, n := d.GetChange("trigger")
+		oldTriggers := o.(*schema.Set)
+		newTriggers := n.(*schema.Set)
+
+		if err := removeOrgTriggers(client, d.Id(), oldTriggers.Difference(newTriggers).List()); err != nil {
+			return err
+		}
+
+		if err := addOrgTriggers(client, d.Id(), newTriggers.Difference(oldTriggers).List()); err != nil {
+			return err
+		}
+	}
+
+	return orgReadFunc(d, meta)
+}
+
+func orgDeleteFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	return client.DeleteOrg(d.Id())
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+// resourcePermission grants a single permission to a user or role.
+func resourcePermission() *schema.Resource {
+	return &schema.Resource{
+		SchemaVersion: 1,
+		Create:        permissionCreateFunc,
+		Read:          permissionReadFunc,
+		Delete:        permissionDeleteFunc,
+		CustomizeDiff: permissionPathCustomizeDiffFunc,
+		Schema: map[string]*schema.Schema{
+			"principal_type": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"user", "role"}, false),
+			},
+			"principal": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"path": &schema.Schema{
+				Type:     schema.TypeList,
+				Required: true,
+				ForceNew: true,
+				MinItems: 1,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validatePermissionSegment,
+				},
+			},
+		},
+	}
+}
+
+// getPrincipalCollection maps a principal type to its API collection.
+func getPrincipalCollection(principalType string) string {
+	return principalType + "s"
+}
+
+func getPrincipalPermissions(client *FifoClient, principalType string, uuid string) ([][]string, error) {
+	if principalType == "role" {
+		role, err := client.GetRole(uuid)
+		return role.Permissions, err
+	}
+
+	user, err := client.GetUser(uuid)
+	return user.Permissions, err
+}
+
+func permissionPathCustomizeDiffFunc(d *schema.ResourceDiff, meta interface{}) error {
+	return validatePermissionPath(getPermissionPath(map[string]interface{}{"path": d.Get("path")}))
+}
+
+func permissionCreateFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	principalType := d.Get("principal_type").(string)
+	principal := d.Get("principal").(string)
+	path := getPermissionPath(map[string]interface{}{"path": d.Get("path")})
+
+	// Granting is idempotent, an existing grant is simply adopted.
+	if err := client.GrantPermission(getPrincipalCollection(principalType), principal, path); err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s/%s", principalType, principal, strings.Join(path, "/")))
+
+	return permissionReadFunc(d, meta)
+}
+
+func permissionReadFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	path := getPermissionPath(map[string]interface{}{"path": d.Get("path")})
+	permissions, err := getPrincipalPermissions(client, d.Get("principal_type").(string), d.Get("principal").(string))
+	if err != nil {
+		return err
+	}
+
+	for _, permission := range permissions {
+		if strings.Join(permission, "/") == strings.Join(path, "/") {
+			return nil
+		}
+	}
+
+	// The grant was revoked outside Terraform.
+	d.SetId("")
+
+	return nil
+}
+
+func permissionDeleteFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	path := getPermissionPath(map[string]interface{}{"path": d.Get("path")})
+
+	return client.RevokePermission(getPrincipalCollection(d.Get("principal_type").(string)), d.Get("principal").(string), path)
+}
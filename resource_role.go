@@ -0,0 +1,95 @@
+package main
+
+import "github.com/hashicorp/terraform/helper/schema"
+
+func resourceRole() *schema.Resource {
+	return &schema.Resource{
+		SchemaVersion: 1,
+		Create:        roleCreateFunc,
+		Read:          roleReadFunc,
+		Update:        roleUpdateFunc,
+		Delete:        roleDeleteFunc,
+		CustomizeDiff: permissionCustomizeDiffFunc,
+		Importer: &schema.ResourceImporter{
+			State: roleImportFunc,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"permission": permissionSchema(),
+		},
+	}
+}
+
+func roleCreateFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	id, err := client.CreateRole(d.Get("name").(string))
+	if err != nil {
+		return err
+	}
+
+	d.SetId(id)
+
+	if err := client.StampMetadata("roles", id); err != nil {
+		return err
+	}
+
+	permissions := d.Get("permission").(*schema.Set)
+	if err := updatePermissions(client, "roles", id, schema.NewSet(permissions.F, nil), permissions); err != nil {
+		return err
+	}
+
+	return roleReadFunc(d, meta)
+}
+
+func roleReadFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	role, err := client.GetRole(d.Id())
+	if err != nil {
+		return err
+	}
+
+	d.Set("name", role.Name)
+	d.Set("permission", getManagedPermissions(d.Get("permission").(*schema.Set), role.Permissions))
+
+	return nil
+}
+
+func roleUpdateFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	if d.HasChange("permission") {
+		o, n := d.GetChange("permission")
+		if err := updatePermissions(client, "roles", d.Id(), o.(*schema.Set), n.(*schema.Set)); err != nil {
+			return err
+		}
+	}
+
+	return roleReadFunc(d, meta)
+}
+
+// roleImportFunc seeds the permissions with every grant the role
+// currently has, reads only keep grants already in state.
+func roleImportFunc(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	client := meta.(*FifoClient)
+
+	role, err := client.GetRole(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	d.Set("permission", flattenPermissions(role.Permissions))
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func roleDeleteFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	return client.DeleteRole(d.Id())
+}
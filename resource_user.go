@@ -0,0 +1,413 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceUser() *schema.Resource {
+	return &schema.Resource{
+		SchemaVersion: 1,
+		Create:        userCreateFunc,
+		Read:          userReadFunc,
+		Update:        userUpdateFunc,
+		Delete:        userDeleteFunc,
+		CustomizeDiff: permissionCustomizeDiffFunc,
+		Importer: &schema.ResourceImporter{
+			State: userImportFunc,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"password": &schema.Schema{
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"ssh_keys": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"yubikeys": &schema.Schema{
+				Type:      schema.TypeSet,
+				Optional:  true,
+				Sensitive: true,
+				Elem:      &schema.Schema{Type: schema.TypeString},
+				Set:       schema.HashString,
+			},
+			"metadata": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"org": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"roles": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
+			},
+			"permission": permissionSchema(),
+		},
+	}
+}
+
+// permissionSchema describes a set of permission grants such as
+// ["vms", "<uuid>", "console"], shared by users and roles.
+func permissionSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeSet,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"path": &schema.Schema{
+					Type:     schema.TypeList,
+					Required: true,
+					MinItems: 1,
+					Elem: &schema.Schema{
+						Type:         schema.TypeString,
+						ValidateFunc: validatePermissionSegment,
+					},
+				},
+			},
+		},
+	}
+}
+
+// validatePermissionSegment rejects segments that can't be expressed in a
+// permissions URL.
+func validatePermissionSegment(v interface{}, k string) ([]string, []error) {
+	segment := v.(string)
+	if segment == "" {
+		return nil, []error{fmt.Errorf("%s: permission path segments must not be empty", k)}
+	}
+
+	if strings.Contains(segment, "/") {
+		return nil, []error{fmt.Errorf("%s: permission path segment %q must not contain '/'", k, segment)}
+	}
+
+	return nil, nil
+}
+
+// validatePermissionPath checks the rules that span segments: the "..."
+// wildcard matches the rest of a path so it may only come last.
+func validatePermissionPath(path []string) error {
+	for i, segment := range path {
+		if segment == "..." && i != len(path)-1 {
+			return fmt.Errorf("Permission %s: '...' is only allowed as the last segment", strings.Join(path, "->"))
+		}
+	}
+
+	return nil
+}
+
+// permissionCustomizeDiffFunc validates the permission paths of users and
+// roles at plan time.
+func permissionCustomizeDiffFunc(d *schema.ResourceDiff, meta interface{}) error {
+	for _, p := range d.Get("permission").(*schema.Set).List() {
+		if err := validatePermissionPath(getPermissionPath(p.(map[string]interface{}))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func getPermissionPath(permission map[string]interface{}) []string {
+	segments := permission["path"].([]interface{})
+	path := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		path = append(path, segment.(string))
+	}
+
+	return path
+}
+
+func flattenPermissions(permissions [][]string) []interface{} {
+	result := make([]interface{}, 0, len(permissions))
+	for _, path := range permissions {
+		result = append(result, map[string]interface{}{
+			"path": path,
+		})
+	}
+
+	return result
+}
+
+// getManagedPermissions returns the permissions of managed that are still
+// granted in actual, grants made outside Terraform are ignored.
+func getManagedPermissions(managed *schema.Set, actual [][]string) []interface{} {
+	granted := make(map[string]bool, len(actual))
+	for _, path := range actual {
+		granted[strings.Join(path, "/")] = true
+	}
+
+	var permissions [][]string
+	for _, p := range managed.List() {
+		path := getPermissionPath(p.(map[string]interface{}))
+		if granted[strings.Join(path, "/")] {
+			permissions = append(permissions, path)
+		}
+	}
+
+	return flattenPermissions(permissions)
+}
+
+// updatePermissions grants and revokes the difference between two
+// permission sets one path at a time.
+func updatePermissions(client *FifoClient, collection string, uuid string, o, n *schema.Set) error {
+	for _, p := range o.Difference(n).List() {
+		if err := client.RevokePermission(collection, uuid, getPermissionPath(p.(map[string]interface{}))); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range n.Difference(o).List() {
+		path := getPermissionPath(p.(map[string]interface{}))
+		if err := client.GrantPermission(collection, uuid, path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getYubikeyID returns the id FiFo stores for a yubikey, the first 12
+// characters of any OTP it generates.
+func getYubikeyID(otp string) string {
+	if len(otp) > 12 {
+		return otp[:12]
+	}
+
+	return otp
+}
+
+func updateUserKeys(client *FifoClient, uuid string, o, n map[string]interface{}) error {
+	removed, changed := diffStringMaps(o, n)
+	for _, name := range removed {
+		if err := client.DeleteUserKey(uuid, name); err != nil {
+			return err
+		}
+	}
+
+	for name, key := range changed {
+		if err := client.AddUserKey(uuid, name, key.(string)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func updateUserYubikeys(client *FifoClient, uuid string, o, n *schema.Set) error {
+	for _, otp := range o.Difference(n).List() {
+		if err := client.DeleteUserYubikey(uuid, getYubikeyID(otp.(string))); err != nil {
+			return err
+		}
+	}
+
+	for _, otp := range n.Difference(o).List() {
+		if err := client.AddUserYubikey(uuid, otp.(string)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func updateUserRoles(client *FifoClient, uuid string, o, n *schema.Set) error {
+	for _, role := range o.Difference(n).List() {
+		if err := client.RemoveUserRole(uuid, role.(string)); err != nil {
+			return err
+		}
+	}
+
+	for _, role := range n.Difference(o).List() {
+		if err := client.AddUserRole(uuid, role.(string)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func userCreateFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	id, err := client.CreateUser(d.Get("name").(string), d.Get("password").(string))
+	if err != nil {
+		return err
+	}
+
+	d.SetId(id)
+
+	if err := client.StampMetadata("users", id); err != nil {
+		return err
+	}
+
+	emptyMap := map[string]interface{}{}
+	emptySet := schema.NewSet(schema.HashString, nil)
+
+	if err := updateUserKeys(client, id, emptyMap, d.Get("ssh_keys").(map[string]interface{})); err != nil {
+		return err
+	}
+
+	if err := updateUserYubikeys(client, id, emptySet, d.Get("yubikeys").(*schema.Set)); err != nil {
+		return err
+	}
+
+	if err := updateMetadata(client, "users", id, nil, emptyMap, d.Get("metadata").(map[string]interface{})); err != nil {
+		return err
+	}
+
+	if org := d.Get("org").(string); org != "" {
+		if err := client.SetUserActiveOrg(id, org); err != nil {
+			return err
+		}
+	}
+
+	if err := updateUserRoles(client, id, emptySet, d.Get("roles").(*schema.Set)); err != nil {
+		return err
+	}
+
+	permissions := d.Get("permission").(*schema.Set)
+	if err := updatePermissions(client, "users", id, schema.NewSet(permissions.F, nil), permissions); err != nil {
+		return err
+	}
+
+	return userReadFunc(d, meta)
+}
+
+func userReadFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	user, err := client.GetUser(d.Id())
+	if err != nil {
+		return err
+	}
+
+	// Keys added outside this resource, e.g. by projectfifo_user_ssh_key,
+	// are left alone.
+	keys := make(map[string]interface{}, len(user.Keys))
+	for name, key := range user.Keys {
+		keys[name] = key
+	}
+
+	// FiFo only reports yubikey ids, keep the configured OTP for every id
+	// that is still present. Yubikeys enrolled elsewhere are left alone.
+	enrolled := make(map[string]bool, len(user.Yubikeys))
+	for _, id := range user.Yubikeys {
+		enrolled[id] = true
+	}
+	otps := schema.NewSet(schema.HashString, nil)
+	for _, otp := range d.Get("yubikeys").(*schema.Set).List() {
+		if enrolled[getYubikeyID(otp.(string))] {
+			otps.Add(otp)
+		}
+	}
+
+	d.Set("name", user.Name)
+	d.Set("ssh_keys", getManagedValues(d.Get("ssh_keys").(map[string]interface{}), keys))
+	d.Set("yubikeys", otps)
+	d.Set("metadata", getManagedValues(d.Get("metadata").(map[string]interface{}), user.Metadata))
+	d.Set("org", user.Org)
+	d.Set("roles", getManagedMembers(d.Get("roles").(*schema.Set), user.Roles))
+	d.Set("permission", getManagedPermissions(d.Get("permission").(*schema.Set), user.Permissions))
+
+	return nil
+}
+
+func userUpdateFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	if d.HasChange("password") {
+		if err := client.SetUserPassword(d.Id(), d.Get("password").(string)); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("ssh_keys") {
+		o, n := d.GetChange("ssh_keys")
+		if err := updateUserKeys(client, d.Id(), o.(map[string]interface{}), n.(map[string]interface{})); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("yubikeys") {
+		o, n := d.GetChange("yubikeys")
+		if err := updateUserYubikeys(client, d.Id(), o.(*schema.Set), n.(*schema.Set)); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("metadata") {
+		o, n := d.GetChange("metadata")
+		if err := updateMetadata(client, "users", d.Id(), nil, o.(map[string]interface{}), n.(map[string]interface{})); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("org") {
+		if org := d.Get("org").(string); org != "" {
+			if err := client.SetUserActiveOrg(d.Id(), org); err != nil {
+				return err
+			}
+		}
+	}
+
+	if d.HasChange("roles") {
+		o, n := d.GetChange("roles")
+		if err := updateUserRoles(client, d.Id(), o.(*schema.Set), n.(*schema.Set)); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("permission") {
+		o, n := d.GetChange("permission")
+		if err := updatePermissions(client, "users", d.Id(), o.(*schema.Set), n.(*schema.Set)); err != nil {
+			return err
+		}
+	}
+
+	return userReadFunc(d, meta)
+}
+
+// userImportFunc seeds the attributes that are only read back for managed
+// entries with everything the user currently has. Yubikeys can't be seeded
+// as FiFo never returns their OTPs.
+func userImportFunc(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	client := meta.(*FifoClient)
+
+	user, err := client.GetUser(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	// The provider's own namespace is stamped on creation and isn't part
+	// of the configured metadata.
+	metadata := flattenMetadata(user.Metadata)
+	delete(metadata, client.MetadataNamespace)
+
+	d.Set("ssh_keys", user.Keys)
+	d.Set("metadata", metadata)
+	d.Set("roles", user.Roles)
+	d.Set("permission", flattenPermissions(user.Permissions))
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func userDeleteFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	return client.DeleteUser(d.Id())
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// resourceUserSSHKey manages a single named key of a user, so keys can be
+// added without owning the whole projectfifo_user.
+func resourceUserSSHKey() *schema.Resource {
+	return &schema.Resource{
+		SchemaVersion: 1,
+		Create:        userSSHKeyCreateFunc,
+		Read:          userSSHKeyReadFunc,
+		Update:        userSSHKeyUpdateFunc,
+		Delete:        userSSHKeyDeleteFunc,
+		Importer: &schema.ResourceImporter{
+			State: userSSHKeyImportFunc,
+		},
+		Schema: map[string]*schema.Schema{
+			"user": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"key": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				StateFunc: func(v interface{}) string {
+					return strings.TrimSpace(v.(string))
+				},
+			},
+		},
+	}
+}
+
+func userSSHKeyCreateFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	user := d.Get("user").(string)
+	name := d.Get("name").(string)
+	if err := client.AddUserKey(user, name, strings.TrimSpace(d.Get("key").(string))); err != nil {
+		return err
+	}
+
+	d.SetId(user + "/" + name)
+
+	return userSSHKeyReadFunc(d, meta)
+}
+
+func userSSHKeyReadFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	user, err := client.GetUser(d.Get("user").(string))
+	if err != nil {
+		return err
+	}
+
+	key, found := user.Keys[d.Get("name").(string)]
+	if !found {
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("key", strings.TrimSpace(key))
+
+	return nil
+}
+
+func userSSHKeyUpdateFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	if d.HasChange("key") {
+		if err := client.AddUserKey(d.Get("user").(string), d.Get("name").(string), strings.TrimSpace(d.Get("key").(string))); err != nil {
+			return err
+		}
+	}
+
+	return userSSHKeyReadFunc(d, meta)
+}
+
+func userSSHKeyDeleteFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	return client.DeleteUserKey(d.Get("user").(string), d.Get("name").(string))
+}
+
+// userSSHKeyImportFunc imports keys by "<user uuid>/<key name>".
+func userSSHKeyImportFunc(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.SplitN(d.Id(), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("Unexpected import id %s, expected <user uuid>/<key name>", d.Id())
+	}
+
+	d.Set("user", parts[0])
+	d.Set("name", parts[1])
+
+	return []*schema.ResourceData{d}, nil
+}
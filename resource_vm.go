@@ -1,19 +1,35 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 )
 
+// vmFwRuleNoICMPType marks a firewall rule without an icmp_type, 0 is a
+// valid ICMP type (echo reply).
+const vmFwRuleNoICMPType = -1
+
+// vmSSHWaitTimeout bounds how long creation waits for a new VM to accept
+// SSH connections when wait_for_ssh is set.
+const vmSSHWaitTimeout = 5 * time.Minute
+
 func resourceVm() *schema.Resource {
 	return &schema.Resource{
 		SchemaVersion: 1,
 		Create:        vmCreateFunc,
 		Read:          vmReadFunc,
+		Update:        vmUpdateFunc,
 		Delete:        vmDeleteFunc,
+		CustomizeDiff: vmCustomizeDiffFunc,
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
 				Type:     schema.TypeString,
@@ -30,10 +46,245 @@ func resourceVm() *schema.Resource {
 				Required: true,
 				ForceNew: true,
 			},
+			"state": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 			"ip": &schema.Schema{
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"ssh_user": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"ssh_port": &schema.Schema{
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  22,
+			},
+			"wait_for_ssh": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"resolvers": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.SingleIP(),
+				},
+			},
+			"routes": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"dns_domain": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"maintain_resolvers": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"kvm": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				ForceNew: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"disk": &schema.Schema{
+							Type:     schema.TypeList,
+							Optional: true,
+							ForceNew: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"size": &schema.Schema{
+										Type:         schema.TypeInt,
+										Required:     true,
+										ForceNew:     true,
+										ValidateFunc: validation.IntAtLeast(1),
+									},
+									"model": &schema.Schema{
+										Type:         schema.TypeString,
+										Optional:     true,
+										ForceNew:     true,
+										ValidateFunc: validation.StringInSlice([]string{"virtio", "ide", "scsi"}, false),
+									},
+									"boot": &schema.Schema{
+										Type:     schema.TypeBool,
+										Optional: true,
+										Default:  false,
+										ForceNew: true,
+									},
+								},
+							},
+						},
+						"nic_driver": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							ForceNew:     true,
+							ValidateFunc: validation.StringInSlice([]string{"virtio", "e1000", "rtl8139"}, false),
+						},
+						"disk_driver": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							ForceNew:     true,
+							ValidateFunc: validation.StringInSlice([]string{"virtio", "ide", "scsi"}, false),
+						},
+						"cpu_type": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							ForceNew:     true,
+							ValidateFunc: validation.StringInSlice([]string{"qemu64", "host"}, false),
+						},
+						"vnc_port": &schema.Schema{
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ForceNew:     true,
+							ValidateFunc: validation.IntBetween(-1, 65535),
+						},
+						"vnc_password": &schema.Schema{
+							Type:      schema.TypeString,
+							Optional:  true,
+							ForceNew:  true,
+							Sensitive: true,
+						},
+					},
+				},
+			},
+			"requirements": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				ForceNew: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"rule": &schema.Schema{
+							Type:     schema.TypeList,
+							Optional: true,
+							ForceNew: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"weight": &schema.Schema{
+										Type:         schema.TypeString,
+										Required:     true,
+										ForceNew:     true,
+										ValidateFunc: validation.StringInSlice([]string{"must", "cant", "should"}, false),
+									},
+									"score": &schema.Schema{
+										Type:     schema.TypeInt,
+										Optional: true,
+										Default:  1,
+										ForceNew: true,
+									},
+									"attribute": &schema.Schema{
+										Type:     schema.TypeString,
+										Required: true,
+										ForceNew: true,
+									},
+									"condition": &schema.Schema{
+										Type:     schema.TypeString,
+										Required: true,
+										ForceNew: true,
+										ValidateFunc: validation.StringInSlice([]string{
+											">=", ">", "=<", "<", "=:=", "=/=",
+											"subset", "superset", "disjoint", "element", "allowed",
+										}, false),
+									},
+									"value": &schema.Schema{
+										Type:     schema.TypeString,
+										Required: true,
+										ForceNew: true,
+									},
+								},
+							},
+						},
+						"hypervisor": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							ForceNew: true,
+						},
+						"grouping": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							ForceNew: true,
+						},
+					},
+				},
+			},
+			"hypervisor": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"owner": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"groupings": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
+			},
+			"services": servicesSchema(),
+			"tags": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"tags_all": &schema.Schema{
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"firewall_rule": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"direction": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice([]string{"inbound", "outbound"}, false),
+						},
+						"action": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice([]string{"allow", "block"}, false),
+						},
+						"protocol": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice([]string{"tcp", "udp", "icmp"}, false),
+						},
+						"target": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "all",
+						},
+						"ports": &schema.Schema{
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeInt},
+						},
+						"icmp_type": &schema.Schema{
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      vmFwRuleNoICMPType,
+							ValidateFunc: validation.IntBetween(vmFwRuleNoICMPType, 255),
+						},
+					},
+				},
+			},
 			"config": &schema.Schema{
 				Type:     schema.TypeSet,
 				Required: true,
@@ -62,6 +313,24 @@ func resourceVm() *schema.Resource {
 							Elem:     &schema.Schema{Type: schema.TypeString},
 							ForceNew: true,
 						},
+						"ips": &schema.Schema{
+							Type:         schema.TypeMap,
+							Optional:     true,
+							Elem:         &schema.Schema{Type: schema.TypeString},
+							ValidateFunc: validateVMStaticIPs,
+							ForceNew:     true,
+						},
+						"ssh_keys": &schema.Schema{
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+							ForceNew: true,
+						},
+						"ssh_keys_user": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							ForceNew: true,
+						},
 					},
 				},
 			},
@@ -77,23 +346,466 @@ func getVMNetworkConfig(networks map[string]interface{}) VMNetworkConfigCreate {
 	return networkConfig
 }
 
+func getVMResolvers(resolvers []interface{}) []string {
+	result := make([]string, 0, len(resolvers))
+	for _, resolver := range resolvers {
+		result = append(result, resolver.(string))
+	}
+
+	return result
+}
+
+func getVMRoutes(routes map[string]interface{}) map[string]string {
+	result := make(map[string]string, len(routes))
+	for destination, gateway := range routes {
+		result[destination] = gateway.(string)
+	}
+
+	return result
+}
+
+// setVMKVMConfig copies the settings of a kvm block onto the create config.
+func setVMKVMConfig(config *VMConfigCreate, kvm map[string]interface{}) {
+	for _, d := range kvm["disk"].([]interface{}) {
+		disk := d.(map[string]interface{})
+		config.Disks = append(config.Disks, VMDisk{
+			Size:  disk["size"].(int),
+			Model: disk["model"].(string),
+			Boot:  disk["boot"].(bool),
+		})
+	}
+
+	config.NicDriver = kvm["nic_driver"].(string)
+	config.DiskDriver = kvm["disk_driver"].(string)
+	config.CPUType = kvm["cpu_type"].(string)
+	config.VNCPort = kvm["vnc_port"].(int)
+	config.VNCPassword = kvm["vnc_password"].(string)
+}
+
+// getVMRequirementValue sends numeric values as numbers so FiFo can compare
+// them against numeric hypervisor attributes.
+func getVMRequirementValue(value string) interface{} {
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		return number
+	}
+
+	return value
+}
+
+// setVMRequirements copies a requirements block onto the create config. A
+// pinned hypervisor becomes a must rule on the hypervisor uuid, and cluster
+// anti-affinity is handled by FiFo once the VM joins the grouping.
+func setVMRequirements(config *VMConfigCreate, requirements map[string]interface{}) {
+	for _, r := range requirements["rule"].([]interface{}) {
+		rule := r.(map[string]interface{})
+
+		var weight interface{} = rule["weight"].(string)
+		if weight == "should" {
+			weight = rule["score"].(int)
+		}
+
+		config.Requirements = append(config.Requirements, VMRequirement{
+			Weight:    weight,
+			Attribute: rule["attribute"].(string),
+			Condition: rule["condition"].(string),
+			Value:     getVMRequirementValue(rule["value"].(string)),
+		})
+	}
+
+	if hypervisor := requirements["hypervisor"].(string); hypervisor != "" {
+		config.Requirements = append(config.Requirements, VMRequirement{
+			Weight:    "must",
+			Attribute: "uuid",
+			Condition: "=:=",
+			Value:     hypervisor,
+		})
+	}
+
+	config.Grouping = requirements["grouping"].(string)
+}
+
+func getVMSSHKeys(keys []interface{}) []string {
+	sshKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		sshKeys = append(sshKeys, strings.TrimSpace(key.(string)))
+	}
+
+	return sshKeys
+}
+
+func getVMStaticIPs(ips map[string]interface{}) map[string]string {
+	result := make(map[string]string, len(ips))
+	for nic, ip := range ips {
+		result[nic] = ip.(string)
+	}
+
+	return result
+}
+
+// validateVMStaticIPs checks that every requested address is a valid IPv4
+// address.
+func validateVMStaticIPs(v interface{}, k string) ([]string, []error) {
+	var errors []error
+	for nic, ip := range v.(map[string]interface{}) {
+		if net.ParseIP(ip.(string)).To4() == nil {
+			errors = append(errors, fmt.Errorf("%s: %s for %s is not a valid IPv4 address", k, ip, nic))
+		}
+	}
+
+	return nil, errors
+}
+
+// getVMHeldIPs returns the static IPs a VM already claimed, keyed by network
+// and address, so a changed config doesn't trip over its own addresses.
+func getVMHeldIPs(config *schema.Set) map[string]bool {
+	held := make(map[string]bool)
+	for _, c := range config.List() {
+		cfg := c.(map[string]interface{})
+		networks := cfg["networks"].(map[string]interface{})
+		for nic, ip := range cfg["ips"].(map[string]interface{}) {
+			if network, ok := networks[nic]; ok {
+				held[network.(string)+":"+ip.(string)] = true
+			}
+		}
+	}
+
+	return held
+}
+
+// validateVMStaticIP checks that a requested address lies within one of the
+// network's ipranges and is neither its gateway nor claimed yet.
+func validateVMStaticIP(client *FifoClient, network string, ip string) error {
+	address, err := ipToUint32(ip)
+	if err != nil {
+		return err
+	}
+
+	nw, err := client.GetNetwork(network)
+	if err != nil {
+		return err
+	}
+
+	for _, uuid := range nw.IPRanges {
+		iprange, err := client.GetIpRange(uuid)
+		if err != nil {
+			return err
+		}
+
+		first, err := ipToUint32(iprange.First)
+		if err != nil {
+			return err
+		}
+
+		last, err := ipToUint32(iprange.Last)
+		if err != nil {
+			return err
+		}
+
+		if address < first || address > last {
+			continue
+		}
+
+		if ip == iprange.Gateway {
+			return fmt.Errorf("IP %s is the gateway of iprange %s", ip, iprange.Name)
+		}
+
+		for _, used := range iprange.Used {
+			if used == ip {
+				return fmt.Errorf("IP %s is already claimed in iprange %s", ip, iprange.Name)
+			}
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("IP %s is not within any iprange of network %s", ip, nw.Name)
+}
+
 func getVMConfig(cfg map[string]interface{}) VMConfigCreate {
 	config := VMConfigCreate{
 		Alias:    cfg["alias"].(string),
 		Autoboot: cfg["autoboot"].(bool),
 		Hostname: cfg["hostname"].(string),
 		Networks: getVMNetworkConfig(cfg["networks"].(map[string]interface{})),
+		IPs:      getVMStaticIPs(cfg["ips"].(map[string]interface{})),
+		SSHKeys:  strings.Join(getVMSSHKeys(cfg["ssh_keys"].([]interface{})), "\n"),
 	}
 
 	return config
 }
 
+// getUserSSHKeys returns the public keys stored on a FiFo user account,
+// ordered by key name so the resulting authorized_keys file is stable.
+func getUserSSHKeys(client *FifoClient, uuid string) ([]string, error) {
+	user, err := client.GetUser(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(user.Keys))
+	for name := range user.Keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sshKeys := make([]string, 0, len(names))
+	for _, name := range names {
+		sshKeys = append(sshKeys, strings.TrimSpace(user.Keys[name]))
+	}
+
+	return sshKeys, nil
+}
+
+// getVMFwRule converts a firewall_rule block into the rule document FiFo
+// expects. Targets are either "all", a single IP or a CIDR subnet.
+func getVMFwRule(r map[string]interface{}) VMFwRule {
+	rule := VMFwRule{
+		Action:    r["action"].(string),
+		Direction: r["direction"].(string),
+		Protocol:  r["protocol"].(string),
+		Target:    "all",
+	}
+
+	target := r["target"].(string)
+	if _, subnet, err := net.ParseCIDR(target); err == nil {
+		mask, _ := subnet.Mask.Size()
+		rule.Target = map[string]interface{}{
+			"subnet": subnet.IP.String(),
+			"mask":   mask,
+		}
+	} else if target != "" && target != "all" {
+		rule.Target = map[string]interface{}{
+			"ip": target,
+		}
+	}
+
+	if rule.Protocol == "icmp" {
+		rule.Filters = map[string]interface{}{
+			"type": r["icmp_type"].(int),
+		}
+	} else if ports := r["ports"].(*schema.Set); ports.Len() > 0 {
+		portList := make([]int, 0, ports.Len())
+		for _, port := range ports.List() {
+			portList = append(portList, port.(int))
+		}
+		sort.Ints(portList)
+
+		rule.Filters = map[string]interface{}{
+			"ports": portList,
+		}
+	}
+
+	return rule
+}
+
+// flattenVMFwRule is the inverse of getVMFwRule for rules read back from
+// the VM document.
+func flattenVMFwRule(rule VMFwRule) map[string]interface{} {
+	r := map[string]interface{}{
+		"action":    rule.Action,
+		"direction": rule.Direction,
+		"protocol":  rule.Protocol,
+		"target":    "all",
+		"ports":     []interface{}{},
+		"icmp_type": vmFwRuleNoICMPType,
+	}
+
+	if target, ok := rule.Target.(map[string]interface{}); ok {
+		if ip, ok := target["ip"].(string); ok {
+			r["target"] = ip
+		} else if subnet, ok := target["subnet"].(string); ok {
+			mask, _ := target["mask"].(float64)
+			r["target"] = fmt.Sprintf("%s/%d", subnet, int(mask))
+		}
+	}
+
+	if filters, ok := rule.Filters.(map[string]interface{}); ok {
+		if ports, ok := filters["ports"].([]interface{}); ok {
+			portNumbers := make([]int, 0, len(ports))
+			for _, port := range ports {
+				if p, ok := port.(float64); ok {
+					portNumbers = append(portNumbers, int(p))
+				}
+			}
+			sort.Ints(portNumbers)
+
+			portList := make([]interface{}, 0, len(portNumbers))
+			for _, port := range portNumbers {
+				portList = append(portList, port)
+			}
+			r["ports"] = portList
+		}
+
+		if icmpType, ok := filters["type"].(float64); ok {
+			r["icmp_type"] = int(icmpType)
+		}
+	}
+
+	return r
+}
+
+// vmFwRuleKey builds a comparable key for a rule so rules in the
+// configuration can be matched against the ids FiFo assigned to them.
+func vmFwRuleKey(rule VMFwRule) (string, error) {
+	normalized, err := normalizeVMFwRule(rule)
+	if err != nil {
+		return "", err
+	}
+
+	document, _ := json.Marshal(flattenVMFwRule(normalized))
+
+	return string(document), nil
+}
+
+// normalizeVMFwRule round-trips a rule through JSON so locally built rules
+// and rules decoded from the API share the same representation.
+func normalizeVMFwRule(rule VMFwRule) (VMFwRule, error) {
+	rule.ID = 0
+	document, _ := json.Marshal(rule)
+
+	normalized := VMFwRule{}
+	if err := json.Unmarshal(document, &normalized); err != nil {
+		return VMFwRule{}, err
+	}
+
+	return normalized, nil
+}
+
+func flattenVMFwRules(rules []VMFwRule) []interface{} {
+	result := make([]interface{}, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, flattenVMFwRule(rule))
+	}
+
+	return result
+}
+
+func addVMFwRules(client *FifoClient, uuid string, rules []interface{}) error {
+	for _, r := range rules {
+		rule := getVMFwRule(r.(map[string]interface{}))
+		if err := client.AddVmFwRule(uuid, &rule); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func removeVMFwRules(client *FifoClient, uuid string, rules []interface{}) error {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	vm, err := client.GetVm(uuid)
+	if err != nil {
+		return err
+	}
+
+	ruleIDs := make(map[string]int)
+	for _, rule := range vm.FwRules {
+		key, err := vmFwRuleKey(rule)
+		if err != nil {
+			return err
+		}
+
+		ruleIDs[key] = rule.ID
+	}
+
+	for _, r := range rules {
+		key, err := vmFwRuleKey(getVMFwRule(r.(map[string]interface{})))
+		if err != nil {
+			return err
+		}
+
+		id, found := ruleIDs[key]
+		if !found {
+			continue
+		}
+
+		if err := client.DeleteVmFwRule(uuid, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateVMGroupings joins and leaves groupings one at a time.
+func updateVMGroupings(client *FifoClient, uuid string, o, n *schema.Set) error {
+	for _, grouping := range o.Difference(n).List() {
+		if err := client.RemoveGroupingElement(grouping.(string), uuid); err != nil {
+			return err
+		}
+	}
+
+	for _, grouping := range n.Difference(o).List() {
+		if err := client.AddGroupingElement(grouping.(string), uuid); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getVMSSHUser picks the default login user for a dataset. FiFo only
+// reports the OS family, so Ubuntu cloud images, which disable root logins,
+// are recognised by name. Everything else accepts root.
+func getVMSSHUser(ds Dataset) string {
+	if strings.ToLower(ds.OS) == "linux" && strings.Contains(strings.ToLower(ds.Name), "ubuntu") {
+		return "ubuntu"
+	}
+
+	return "root"
+}
+
+// waitForSSH blocks until a TCP connection can be made to the given address
+// or the timeout expires.
+func waitForSSH(address string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		conn, err := net.DialTimeout("tcp", address, 5*time.Second)
+		if err == nil {
+			return conn.Close()
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("Timed out waiting for SSH on %s: %s", address, err)
+		}
+
+		time.Sleep(1 * time.Second)
+	}
+}
+
 func vmCreateFunc(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*FifoClient)
+	cfg := d.Get("config").(*schema.Set).List()[0].(map[string]interface{})
 	vm := VMCreate{
 		Dataset: d.Get("dataset").(string),
 		Package: d.Get("package").(string),
-		Config:  getVMConfig(d.Get("config").(*schema.Set).List()[0].(map[string]interface{})),
+		Config:  getVMConfig(cfg),
+	}
+
+	vm.Config.Resolvers = getVMResolvers(d.Get("resolvers").([]interface{}))
+	vm.Config.Routes = getVMRoutes(d.Get("routes").(map[string]interface{}))
+	vm.Config.DNSDomain = d.Get("dns_domain").(string)
+	vm.Config.MaintainResolvers = d.Get("maintain_resolvers").(bool)
+
+	if kvm := d.Get("kvm").([]interface{}); len(kvm) > 0 && kvm[0] != nil {
+		setVMKVMConfig(&vm.Config, kvm[0].(map[string]interface{}))
+	}
+
+	if requirements := d.Get("requirements").([]interface{}); len(requirements) > 0 && requirements[0] != nil {
+		setVMRequirements(&vm.Config, requirements[0].(map[string]interface{}))
+	}
+
+	if user := cfg["ssh_keys_user"].(string); user != "" {
+		userKeys, err := getUserSSHKeys(client, user)
+		if err != nil {
+			return err
+		}
+
+		sshKeys := append(getVMSSHKeys(cfg["ssh_keys"].([]interface{})), userKeys...)
+		vm.Config.SSHKeys = strings.Join(sshKeys, "\n")
 	}
 
 	id, err := client.CreateVm(&vm)
@@ -119,6 +831,7 @@ func vmCreateFunc(d *schema.ResourceData, meta interface{}) error {
 				d.Set("dataset", vm.Dataset)
 				d.Set("state", vm.State)
 				d.Set("ip", vm.Config.Networks[0].IP)
+				d.Set("hypervisor", vm.Hypervisor)
 
 				break
 			}
@@ -129,9 +842,169 @@ func vmCreateFunc(d *schema.ResourceData, meta interface{}) error {
 
 	d.SetId(id)
 
+	owner := d.Get("owner").(string)
+	if owner == "" {
+		owner = client.DefaultOrg
+	}
+
+	if owner != "" {
+		if err := client.SetVmOwner(id, owner); err != nil {
+			return err
+		}
+
+		d.Set("owner", owner)
+	}
+
+	if err := updateVMGroupings(client, id, schema.NewSet(schema.HashString, nil), d.Get("groupings").(*schema.Set)); err != nil {
+		return err
+	}
+
+	if err := setServices(client, "vms", id, d.Get("services").(map[string]interface{})); err != nil {
+		return err
+	}
+
+	if err := updateMetadata(client, "vms", id, []string{client.MetadataNamespace}, map[string]interface{}{}, d.Get("tags_all").(map[string]interface{})); err != nil {
+		return err
+	}
+
+	if err := addVMFwRules(client, id, d.Get("firewall_rule").(*schema.Set).List()); err != nil {
+		return err
+	}
+
+	sshUser := d.Get("ssh_user").(string)
+	if sshUser == "" {
+		ds, err := client.GetDataset(d.Get("dataset").(string))
+		if err != nil {
+			return err
+		}
+
+		sshUser = getVMSSHUser(ds)
+		d.Set("ssh_user", sshUser)
+	}
+
+	ip := d.Get("ip").(string)
+	port := strconv.Itoa(d.Get("ssh_port").(int))
+	d.SetConnInfo(map[string]string{
+		"type": "ssh",
+		"host": ip,
+		"user": sshUser,
+		"port": port,
+	})
+
+	if d.Get("wait_for_ssh").(bool) {
+		if err := waitForSSH(net.JoinHostPort(ip, port), vmSSHWaitTimeout); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateVMFwRule rejects settings that don't apply to the rule's
+// protocol, they would be dropped from the request and never settle.
+func validateVMFwRule(r map[string]interface{}) error {
+	icmpType := r["icmp_type"].(int)
+	ports := r["ports"].(*schema.Set)
+
+	if r["protocol"].(string) == "icmp" {
+		if icmpType == vmFwRuleNoICMPType {
+			return fmt.Errorf("Firewall rules with protocol icmp require icmp_type")
+		}
+
+		if ports.Len() > 0 {
+			return fmt.Errorf("Firewall rules with protocol icmp can't set ports")
+		}
+
+		return nil
+	}
+
+	if icmpType != vmFwRuleNoICMPType {
+		return fmt.Errorf("Firewall rules with protocol %s can't set icmp_type", r["protocol"].(string))
+	}
+
+	return nil
+}
+
+func vmCustomizeDiffFunc(d *schema.ResourceDiff, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	for _, r := range d.Get("firewall_rule").(*schema.Set).List() {
+		if err := validateVMFwRule(r.(map[string]interface{})); err != nil {
+			return err
+		}
+	}
+
+	// The dataset may be gone from FiFo once a VM is provisioned, so it's
+	// only looked up when the combination is new.
+	changed := d.Id() == "" || d.HasChange("dataset") || d.HasChange("kvm")
+	if kvm := d.Get("kvm").([]interface{}); len(kvm) > 0 && changed && d.NewValueKnown("dataset") {
+		dataset := d.Get("dataset").(string)
+		ds, err := client.GetDataset(dataset)
+		if err != nil {
+			return err
+		}
+
+		if ds.Type != "kvm" {
+			return fmt.Errorf("The kvm block can only be used with KVM datasets, %s (%s) is of type %s", ds.Name, dataset, ds.Type)
+		}
+	}
+
+	if (d.Id() == "" || d.HasChange("config")) && d.NewValueKnown("config") {
+		o, n := d.GetChange("config")
+		held := getVMHeldIPs(o.(*schema.Set))
+		for _, c := range n.(*schema.Set).List() {
+			cfg := c.(map[string]interface{})
+			networks := cfg["networks"].(map[string]interface{})
+
+			for nic, ip := range cfg["ips"].(map[string]interface{}) {
+				network, ok := networks[nic]
+				if !ok {
+					return fmt.Errorf("IP %s is requested for %s which has no network", ip, nic)
+				}
+
+				if held[network.(string)+":"+ip.(string)] {
+					continue
+				}
+
+				if err := validateVMStaticIP(client, network.(string), ip.(string)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if !d.NewValueKnown("tags") {
+		return d.SetNewComputed("tags_all")
+	}
+
+	tags := mergeTags(client.DefaultMetadata, client.DefaultTags, d.Get("tags").(map[string]interface{}))
+	if !reflect.DeepEqual(d.Get("tags_all").(map[string]interface{}), tags) {
+		return d.SetNew("tags_all", tags)
+	}
+
 	return nil
 }
 
+// mergeTags layers tag maps over each other, later maps win. VMs layer
+// their own tags over the provider's default tags and default metadata.
+func mergeTags(layers ...map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	for _, layer := range layers {
+		for key, value := range layer {
+			result[key] = value
+		}
+	}
+
+	return result
+}
+
+// getVMTags returns the tags stored in the VM's metadata namespace.
+func getVMTags(client *FifoClient, vm VM) map[string]interface{} {
+	namespace, _ := vm.Metadata[client.MetadataNamespace].(map[string]interface{})
+
+	return namespace
+}
+
 func vmReadFunc(d *schema.ResourceData, meta interface{}) error {
 
 	client := meta.(*FifoClient)
@@ -145,10 +1018,89 @@ func vmReadFunc(d *schema.ResourceData, meta interface{}) error {
 	d.Set("dataset", vm.Dataset)
 	d.Set("state", vm.State)
 	d.Set("ip", vm.Config.Networks[0].IP)
+	d.Set("hypervisor", vm.Hypervisor)
+	d.Set("owner", vm.Owner)
+	d.Set("groupings", getManagedMembers(d.Get("groupings").(*schema.Set), vm.Groupings))
+	d.Set("services", getManagedServices(d.Get("services").(map[string]interface{}), vm.Services))
+	d.Set("tags", getManagedValues(d.Get("tags").(map[string]interface{}), getVMTags(client, vm)))
+	d.Set("tags_all", getManagedValues(d.Get("tags_all").(map[string]interface{}), getVMTags(client, vm)))
+	d.Set("resolvers", vm.Config.Resolvers)
+	d.Set("routes", vm.Config.Routes)
+	d.Set("dns_domain", vm.Config.DNSDomain)
+	d.Set("maintain_resolvers", vm.Config.MaintainResolvers)
+	d.Set("firewall_rule", flattenVMFwRules(vm.FwRules))
 
 	return nil
 }
 
+func vmUpdateFunc(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*FifoClient)
+
+	if d.HasChange("owner") {
+		if err := client.SetVmOwner(d.Id(), d.Get("owner").(string)); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("groupings") {
+		o, n := d.GetChange("groupings")
+		if err := updateVMGroupings(client, d.Id(), o.(*schema.Set), n.(*schema.Set)); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("services") {
+		o, n := d.GetChange("services")
+		_, changed := diffStringMaps(o.(map[string]interface{}), n.(map[string]interface{}))
+		if err := setServices(client, "vms", d.Id(), changed); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("tags_all") {
+		o, n := d.GetChange("tags_all")
+		if err := updateMetadata(client, "vms", d.Id(), []string{client.MetadataNamespace}, o.(map[string]interface{}), n.(map[string]interface{})); err != nil {
+			return err
+		}
+	}
+
+	config := make(map[string]interface{})
+	if d.HasChange("resolvers") {
+		config["resolvers"] = getVMResolvers(d.Get("resolvers").([]interface{}))
+	}
+	if d.HasChange("routes") {
+		config["routes"] = getVMRoutes(d.Get("routes").(map[string]interface{}))
+	}
+	if d.HasChange("dns_domain") {
+		config["dns_domain"] = d.Get("dns_domain").(string)
+	}
+	if d.HasChange("maintain_resolvers") {
+		config["maintain_resolvers"] = d.Get("maintain_resolvers").(bool)
+	}
+
+	if len(config) > 0 {
+		if err := client.UpdateVmConfig(d.Id(), config); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("firewall_rule") {
+		o, n := d.GetChange("firewall_rule")
+		oldRules := o.(*schema.Set)
+		newRules := n.(*schema.Set)
+
+		if err := removeVMFwRules(client, d.Id(), oldRules.Difference(newRules).List()); err != nil {
+			return err
+		}
+
+		if err := addVMFwRules(client, d.Id(), newRules.Difference(oldRules).List()); err != nil {
+			return err
+		}
+	}
+
+	return vmReadFunc(d, meta)
+}
+
 func vmDeleteFunc(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*FifoClient)
 
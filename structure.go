@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// servicesSchema describes a map of SMF service FMRIs to their desired
+// state, shared by hypervisors and VMs.
+func servicesSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeMap,
+		Optional:     true,
+		Elem:         &schema.Schema{Type: schema.TypeString},
+		ValidateFunc: validateServiceStates,
+	}
+}
+
+func validateServiceStates(v interface{}, k string) ([]string, []error) {
+	var errors []error
+	for fmri, state := range v.(map[string]interface{}) {
+		switch state.(string) {
+		case "enabled", "disabled", "clear":
+		default:
+			errors = append(errors, fmt.Errorf("%s: service %s must be enabled, disabled or clear, got %q", k, fmri, state))
+		}
+	}
+
+	return nil, errors
+}
+
+// diffStringMaps returns the keys removed from o and the entries of n that
+// are new or changed.
+func diffStringMaps(o, n map[string]interface{}) ([]string, map[string]interface{}) {
+	removed := make([]string, 0)
+	for key := range o {
+		if _, ok := n[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+
+	changed := make(map[string]interface{})
+	for key, value := range n {
+		if old, ok := o[key]; !ok || old != value {
+			changed[key] = value
+		}
+	}
+
+	return removed, changed
+}
+
+// getManagedValues picks the entries of actual whose keys are managed by
+// Terraform, keys set outside Terraform are ignored.
+func getManagedValues(managed map[string]interface{}, actual map[string]interface{}) map[string]string {
+	result := make(map[string]string)
+	for key := range managed {
+		if value, ok := actual[key]; ok {
+			result[key] = fmt.Sprint(value)
+		}
+	}
+
+	return result
+}
+
+// getServiceAction maps a desired service state to the FiFo service action.
+func getServiceAction(state string) string {
+	switch state {
+	case "enabled":
+		return "enable"
+	case "disabled":
+		return "disable"
+	}
+
+	return state
+}
+
+// getServiceState maps the SMF state reported by FiFo back to the desired
+// state in the configuration. A "clear" stays satisfied as long as the
+// service is out of maintenance.
+func getServiceState(desired string, actual string) string {
+	switch actual {
+	case "online":
+		if desired == "clear" {
+			return desired
+		}
+		return "enabled"
+	case "disabled":
+		if desired == "clear" {
+			return desired
+		}
+		return "disabled"
+	}
+
+	return actual
+}
+
+func getManagedServices(managed map[string]interface{}, actual map[string]string) map[string]string {
+	result := make(map[string]string)
+	for fmri, desired := range managed {
+		if state, ok := actual[fmri]; ok {
+			result[fmri] = getServiceState(desired.(string), state)
+		}
+	}
+
+	return result
+}
+
+func setServices(client *FifoClient, collection string, uuid string, services map[string]interface{}) error {
+	for fmri, state := range services {
+		if err := client.SetService(collection, uuid, fmri, getServiceAction(state.(string))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func updateMetadata(client *FifoClient, collection string, uuid string, path []string, o, n map[string]interface{}) error {
+	removed, changed := diffStringMaps(o, n)
+	for _, key := range removed {
+		if err := client.DeleteMetadata(collection, uuid, append(path, key)); err != nil {
+			return err
+		}
+	}
+
+	if len(changed) > 0 {
+		return client.SetMetadata(collection, uuid, path, changed)
+	}
+
+	return nil
+}
+
+// getManagedMembers returns the members of managed that are still part of
+// actual.
+func getManagedMembers(managed *schema.Set, actual []string) *schema.Set {
+	present := make(map[string]bool, len(actual))
+	for _, member := range actual {
+		present[member] = true
+	}
+
+	members := schema.NewSet(schema.HashString, nil)
+	for _, member := range managed.List() {
+		if present[member.(string)] {
+			members.Add(member)
+		}
+	}
+
+	return members
+}
+
+// flattenMetadata renders a metadata document as a flat string map. Nested
+// values are JSON encoded.
+func flattenMetadata(metadata map[string]interface{}) map[string]string {
+	result := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		if s, ok := value.(string); ok {
+			result[key] = s
+			continue
+		}
+
+		document, _ := json.Marshal(value)
+		result[key] = string(document)
+	}
+
+	return result
+}